@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
@@ -41,13 +42,17 @@ var (
 
 func applyFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
 	// Read file
-	fdl, err := service.ReadFDL(args[0])
+	values, err := getFDLValues(cmd)
+	if err != nil {
+		return err
+	}
+	fdl, err := service.ReadFDLWithValues(args[0], values)
 	if err != nil {
 		return err
 	}
@@ -59,6 +64,95 @@ func applyFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Pre-loop to check all clusters and get its MinIO storage provider
+	clusters, err := resolveFDLClusters(cmd, conf, fdl)
+	if err != nil {
+		return err
+	}
+
+	explicitID, _ := cmd.Flags().GetString("fdl-id")
+	if explicitID == "" {
+		explicitID = fdl.ID
+	}
+	fdlID := service.FDLIdentifier(args[0], explicitID, serviceNameOverride)
+
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		return planFDL(cmd, conf, fdl, clusters, fdlID, path.Base(args[0]))
+	}
+
+	targets, defined, err := prepareFDLTargets(cmd, conf, fdl, clusters, fdlID)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot the current definitions to be able to roll back on failure
+	var tx *applyTransaction
+	if atomic, _ := cmd.Flags().GetBool("atomic"); atomic {
+		tx, err = newApplyTransaction(conf, targets, cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Applying file \"%s\"...\n", path.Base(args[0]))
+
+	for _, target := range targets {
+		svc := target.svc
+		targetCluster := target.cluster
+
+		msg := fmt.Sprintf(" Creating service \"%s\" in cluster \"%s\"", svc.Name, targetCluster)
+		method := http.MethodPost
+
+		// Make and start the spinner
+		s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+		s.Suffix = msg
+		s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
+		s.Start()
+
+		// Check if service exists in cluster in order to create or edit it
+		var exists bool
+		if tx != nil {
+			exists = tx.existed(target)
+		} else {
+			exists, err = serviceExists(svc, conf.Oscar[targetCluster])
+			if err != nil {
+				s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+				s.Stop()
+				return err
+			}
+		}
+		if exists {
+			msg = fmt.Sprintf(" Editing service \"%s\" in cluster \"%s\"", svc.Name, targetCluster)
+			method = http.MethodPut
+			s.Suffix = msg
+			s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
+		}
+
+		// Apply the service
+		err = service.ApplyService(svc, conf.Oscar[targetCluster], method)
+		if err != nil {
+			s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+			s.Stop()
+			if tx != nil {
+				return tx.rollback(err)
+			}
+			return err
+		}
+		s.Stop()
+
+		if tx != nil {
+			tx.record(target)
+		}
+	}
+
+	if prune, _ := cmd.Flags().GetBool("prune"); prune {
+		return pruneFDLServices(cmd, conf, fdlID, defined)
+	}
+
+	return nil
+}
+
+// resolveFDLClusters checks all the clusters referenced in the FDL and returns their definitions indexed by identifier
+func resolveFDLClusters(cmd *cobra.Command, conf *config.Config, fdl *service.FDL) (map[string]types.Cluster, error) {
 	clusters := map[string]types.Cluster{}
 	minioProviders := map[string]*types.MinIOProvider{}
 	for _, element := range fdl.Functions.Oscar {
@@ -66,7 +160,7 @@ func applyFunc(cmd *cobra.Command, args []string) error {
 			default_cluster, _ := cmd.Flags().GetBool("default")
 			targetCluster, errCluster := conf.GetCluster(default_cluster, destinationClusterID, clusterName)
 			if errCluster != nil {
-				return errCluster
+				return nil, errCluster
 			}
 
 			if _, exists := clusters[targetCluster]; exists {
@@ -76,13 +170,13 @@ func applyFunc(cmd *cobra.Command, args []string) error {
 			// Check if cluster is defined
 			err := conf.CheckCluster(targetCluster)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			// Get cluster info
 			clusterInfo, err := conf.Oscar[targetCluster].GetClusterConfig()
 			if err != nil {
-				return err
+				return nil, err
 			}
 
 			// Append cluster
@@ -98,75 +192,86 @@ func applyFunc(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Printf("Applying file \"%s\"...\n", path.Base(args[0]))
+	return clusters, nil
+}
 
+// fdlTarget is a service of an FDL file prepared to be applied in its target cluster
+type fdlTarget struct {
+	cluster string
+	svc     *types.Service
+}
+
+// prepareFDLTargets prepares all the services defined in the FDL and returns them along with their names indexed by target cluster
+func prepareFDLTargets(cmd *cobra.Command, conf *config.Config, fdl *service.FDL, clusters map[string]types.Cluster, fdlID string) ([]fdlTarget, map[string]map[string]bool, error) {
+	targets := []fdlTarget{}
+	defined := map[string]map[string]bool{}
 	for _, element := range fdl.Functions.Oscar {
 		for clusterName, svc := range element {
-			default_cluster, _ := cmd.Flags().GetBool("default")
-			targetCluster, errCluster := conf.GetCluster(default_cluster, destinationClusterID, clusterName)
-			if errCluster != nil {
-				return errCluster
-			}
-
-			svc.ClusterID = targetCluster
-
-			if trimmed := strings.TrimSpace(serviceNameOverride); trimmed != "" {
-				overrideServiceName(svc, trimmed)
+			targetCluster, err := prepareFDLService(cmd, conf, svc, clusterName, clusters, fdlID)
+			if err != nil {
+				return nil, nil, err
 			}
+			recordDefinedService(defined, targetCluster, svc.Name)
+			targets = append(targets, fdlTarget{cluster: targetCluster, svc: svc})
+		}
+	}
+	return targets, defined, nil
+}
 
-			msg := fmt.Sprintf(" Creating service \"%s\" in cluster \"%s\"", svc.Name, targetCluster)
-			method := http.MethodPost
+// prepareFDLService completes the definition of a service read from an FDL before applying it and returns its target cluster
+func prepareFDLService(cmd *cobra.Command, conf *config.Config, svc *types.Service, clusterName string, clusters map[string]types.Cluster, fdlID string) (string, error) {
+	default_cluster, _ := cmd.Flags().GetBool("default")
+	targetCluster, errCluster := conf.GetCluster(default_cluster, destinationClusterID, clusterName)
+	if errCluster != nil {
+		return "", errCluster
+	}
 
-			// Make and start the spinner
-			s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
-			s.Suffix = msg
-			s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
-			s.Start()
+	svc.ClusterID = targetCluster
 
-			// Add (and overwrite) clusters
-			if svc.Clusters == nil {
-				// Initialize map
-				svc.Clusters = map[string]types.Cluster{}
-			}
-			for cn, c := range clusters {
-				svc.Clusters[cn] = c
-			}
+	if trimmed := strings.TrimSpace(serviceNameOverride); trimmed != "" {
+		overrideServiceName(svc, trimmed)
+	}
 
-			// Add (and overwrite) MinIO providers
-			if svc.StorageProviders == nil {
-				// Initialize StorageProviders
-				svc.StorageProviders = &types.StorageProviders{}
-			}
-			if svc.StorageProviders.MinIO == nil {
-				// Initialize map
-				svc.StorageProviders.MinIO = map[string]*types.MinIOProvider{}
-			}
+	// Add (and overwrite) clusters
+	if svc.Clusters == nil {
+		// Initialize map
+		svc.Clusters = map[string]types.Cluster{}
+	}
+	for cn, c := range clusters {
+		svc.Clusters[cn] = c
+	}
 
-			// Check if service exists in cluster in order to create or edit it
-			if exists := serviceExists(svc, conf.Oscar[targetCluster]); exists {
-				msg = fmt.Sprintf(" Editing service \"%s\" in cluster \"%s\"", svc.Name, targetCluster)
-				method = http.MethodPut
-				s.Suffix = msg
-				s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
-			}
+	// Add (and overwrite) MinIO providers
+	if svc.StorageProviders == nil {
+		// Initialize StorageProviders
+		svc.StorageProviders = &types.StorageProviders{}
+	}
+	if svc.StorageProviders.MinIO == nil {
+		// Initialize map
+		svc.StorageProviders.MinIO = map[string]*types.MinIOProvider{}
+	}
 
-			// Apply the service
-			err = service.ApplyService(svc, conf.Oscar[targetCluster], method)
-			if err != nil {
-				s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
-				s.Stop()
-				return err
-			}
-			s.Stop()
+	// Stamp the FDL identifier to be able to prune the service when it is removed from the file
+	if fdlID != "" {
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
 		}
+		svc.Labels[service.FDLLabel] = fdlID
 	}
 
-	return nil
+	return targetCluster, nil
 }
 
-func serviceExists(svc *types.Service, c *cluster.Cluster) bool {
+// serviceExists checks if the service is deployed in the cluster. Errors other than not finding it are returned
+func serviceExists(svc *types.Service, c *cluster.Cluster) (bool, error) {
 	_, err := service.GetService(c, svc.Name)
-	return err == nil
+	if errors.Is(err, cluster.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func makeApplyCmd() *cobra.Command {
@@ -182,10 +287,49 @@ func makeApplyCmd() *cobra.Command {
 	applyCmd.Flags().StringVarP(&destinationClusterID, "cluster", "c", "", "override the cluster id defined in the FDL file")
 	applyCmd.Flags().Bool("default", false, "override the cluster id defined in config file")
 	applyCmd.Flags().StringVarP(&serviceNameOverride, "name", "n", "", "override the OSCAR service and primary bucket names during deployment")
+	applyCmd.Flags().Bool("dry-run", false, "show the changes that would be made in the clusters without applying them")
+	applyCmd.Flags().Bool("prune", false, "delete the services previously applied from the FDL file that are no longer defined in it")
+	applyCmd.Flags().String("fdl-id", "", "set the identifier of the FDL file used to prune its services (overrides the \"id\" field of the file)")
+	applyCmd.Flags().BoolP("yes", "y", false, "do not ask for confirmation before pruning services")
+	applyCmd.Flags().Bool("atomic", false, "roll back the services already applied if any of them fails")
+	addFDLValuesFlags(applyCmd)
 
 	return applyCmd
 }
 
+func addFDLValuesFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("set", []string{}, "set a value for the FDL placeholders (KEY=VALUE), can be specified multiple times")
+	cmd.Flags().StringArray("values", []string{}, "YAML file with values for the FDL placeholders, can be specified multiple times")
+	cmd.Flags().Bool("render", false, "resolve the FDL placeholders even if no values are set (e.g. from environment variables only)")
+}
+
+// getFDLValues builds the values to resolve the FDL placeholders, "--set" assignments take precedence over "--values" files.
+// It returns nil, so the FDL is read literally, unless values are set or "--render" is enabled
+func getFDLValues(cmd *cobra.Command) (service.FDLValues, error) {
+	files, _ := cmd.Flags().GetStringArray("values")
+	assignments, _ := cmd.Flags().GetStringArray("set")
+	render, _ := cmd.Flags().GetBool("render")
+	if len(files) == 0 && len(assignments) == 0 && !render {
+		return nil, nil
+	}
+
+	values := service.FDLValues{}
+
+	for _, file := range files {
+		if err := values.MergeFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, assignment := range assignments {
+		if err := values.Set(assignment); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
 func overrideServiceName(svc *types.Service, newName string) {
 	if svc == nil {
 		return
@@ -0,0 +1,119 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+const planValueMaxLength = 60
+
+var (
+	planCreateString    = color.New(color.FgGreen).Sprint("+ ")
+	planChangeString    = color.New(color.FgYellow).Sprint("~ ")
+	planDeleteString    = color.New(color.FgRed).Sprint("- ")
+	planUnchangedString = "= "
+)
+
+// planFDL prints the changes that applying the FDL would make in the clusters without modifying them
+func planFDL(cmd *cobra.Command, conf *config.Config, fdl *service.FDL, clusters map[string]types.Cluster, fdlID string, fileName string) error {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Planning file \"%s\"...\n", fileName)
+
+	targets, defined, err := prepareFDLTargets(cmd, conf, fdl, clusters, fdlID)
+	if err != nil {
+		return err
+	}
+
+	var toCreate, toChange, unchanged, toDelete int
+	for _, target := range targets {
+		svc := target.svc
+		targetCluster := target.cluster
+
+		current, err := service.GetService(conf.Oscar[targetCluster], svc.Name)
+		if err != nil {
+			// Only a missing service means it will be created, any other error makes the plan unreliable
+			if !errors.Is(err, cluster.ErrNotFound) {
+				return fmt.Errorf("unable to get the service \"%s\" from cluster \"%s\": %w", svc.Name, targetCluster, err)
+			}
+			toCreate++
+			fmt.Fprintf(out, "%sservice \"%s\" will be created in cluster \"%s\"\n", planCreateString, svc.Name, targetCluster)
+			continue
+		}
+
+		changes, err := service.DiffServices(current, svc)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			unchanged++
+			fmt.Fprintf(out, "%sservice \"%s\" is up to date in cluster \"%s\"\n", planUnchangedString, svc.Name, targetCluster)
+			continue
+		}
+
+		toChange++
+		fmt.Fprintf(out, "%sservice \"%s\" will be changed in cluster \"%s\"\n", planChangeString, svc.Name, targetCluster)
+		for _, change := range changes {
+			fmt.Fprintf(out, "    %s\n", formatFieldChange(change))
+		}
+	}
+
+	if prune, _ := cmd.Flags().GetBool("prune"); prune {
+		prunable, err := findPrunableServices(cmd.Context(), conf, fdlID, defined, pruneScope(cmd, conf, defined), cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		for _, p := range prunable {
+			toDelete++
+			fmt.Fprintf(out, "%sservice \"%s\" will be deleted from cluster \"%s\"\n", planDeleteString, p.name, p.cluster)
+		}
+		fmt.Fprintf(out, "Plan: %d to create, %d to change, %d to delete, %d unchanged.\n", toCreate, toChange, toDelete, unchanged)
+		return nil
+	}
+
+	fmt.Fprintf(out, "Plan: %d to create, %d to change, %d unchanged.\n", toCreate, toChange, unchanged)
+
+	return nil
+}
+
+func formatFieldChange(change service.FieldChange) string {
+	switch change.Type {
+	case service.FieldAdded:
+		return fmt.Sprintf("+ %s: %s", change.Path, truncatePlanValue(change.New))
+	case service.FieldRemoved:
+		return fmt.Sprintf("- %s: %s", change.Path, truncatePlanValue(change.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", change.Path, truncatePlanValue(change.Old), truncatePlanValue(change.New))
+	}
+}
+
+func truncatePlanValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= planValueMaxLength {
+		return value
+	}
+	return string(runes[:planValueMaxLength]) + "..."
+}
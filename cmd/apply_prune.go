@@ -0,0 +1,154 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+type prunableService struct {
+	cluster string
+	name    string
+}
+
+func recordDefinedService(defined map[string]map[string]bool, clusterID, name string) {
+	if defined[clusterID] == nil {
+		defined[clusterID] = map[string]bool{}
+	}
+	defined[clusterID][name] = true
+}
+
+// pruneScope returns the clusters where the services applied from the FDL are looked for. When the target cluster
+// is overridden only that cluster is checked, otherwise every cluster of the config file is checked, so the services
+// of a cluster that is no longer referenced in the FDL are also pruned
+func pruneScope(cmd *cobra.Command, conf *config.Config, defined map[string]map[string]bool) []string {
+	defaultCluster, _ := cmd.Flags().GetBool("default")
+	if destinationClusterID != "" || defaultCluster {
+		clusterIDs := []string{}
+		for clusterID := range defined {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+		sort.Strings(clusterIDs)
+		return clusterIDs
+	}
+
+	clusterIDs := conf.ClusterIDs()
+	for clusterID := range defined {
+		if !slices.Contains(clusterIDs, clusterID) {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+	}
+	return clusterIDs
+}
+
+// findPrunableServices returns the services labelled as applied from the FDL that are no longer defined in it.
+// The clusters targeted by the FDL must be reachable, the rest of clusters that fail are reported as warnings
+func findPrunableServices(ctx context.Context, conf *config.Config, fdlID string, defined map[string]map[string]bool, clusterIDs []string, warnings io.Writer) ([]prunableService, error) {
+	if fdlID == "" {
+		return nil, errors.New("unable to identify the services applied from the FDL file, please check its file name")
+	}
+
+	prunable := []prunableService{}
+	for _, clusterID := range clusterIDs {
+		svcList, err := service.ListServicesWithContext(ctx, conf.Oscar[clusterID])
+		if err != nil {
+			if _, targeted := defined[clusterID]; targeted {
+				return nil, err
+			}
+			fmt.Fprintf(warnings, "warning: unable to check the services of cluster \"%s\" to prune them: %v\n", clusterID, err)
+			continue
+		}
+
+		names := []string{}
+		for _, svc := range svcList {
+			if svc == nil || svc.Labels[service.FDLLabel] != fdlID {
+				continue
+			}
+			if defined[clusterID][svc.Name] {
+				continue
+			}
+			names = append(names, svc.Name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			prunable = append(prunable, prunableService{cluster: clusterID, name: name})
+		}
+	}
+
+	return prunable, nil
+}
+
+// pruneFDLServices deletes, after confirmation, the services applied from the FDL that are no longer defined in it
+func pruneFDLServices(cmd *cobra.Command, conf *config.Config, fdlID string, defined map[string]map[string]bool) error {
+	prunable, err := findPrunableServices(cmd.Context(), conf, fdlID, defined, pruneScope(cmd, conf, defined), cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(prunable) == 0 {
+		fmt.Fprintln(out, "There are no services to prune")
+		return nil
+	}
+
+	fmt.Fprintln(out, "The following services are no longer defined in the FDL file:")
+	for _, p := range prunable {
+		fmt.Fprintf(out, "  - \"%s\" in cluster \"%s\"\n", p.name, p.cluster)
+	}
+
+	if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+		confirmed, err := confirmAction(cmd, "Do you want to delete them?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Fprintln(out, "Pruning cancelled")
+			return nil
+		}
+	}
+
+	for _, p := range prunable {
+		msg := fmt.Sprintf(" Removing service \"%s\" in cluster \"%s\"", p.name, p.cluster)
+
+		// Make and start the spinner
+		s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+		s.Suffix = msg
+		s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
+		s.Start()
+
+		if err := service.RemoveService(conf.Oscar[p.cluster], p.name); err != nil {
+			s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+			s.Stop()
+			return err
+		}
+		s.Stop()
+	}
+
+	return nil
+}
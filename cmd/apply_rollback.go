@@ -0,0 +1,115 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// applyTransaction keeps the previous definitions of the services of an FDL to restore them if applying fails
+type applyTransaction struct {
+	conf     *config.Config
+	previous map[string]*types.Service
+	applied  []fdlTarget
+	// out receives the progress of the rollback
+	out io.Writer
+}
+
+// newApplyTransaction snapshots the current definition of every target service (nil if the service does not exist yet).
+// It fails if any service cannot be retrieved, since it would not be possible to roll it back
+func newApplyTransaction(conf *config.Config, targets []fdlTarget, out io.Writer) (*applyTransaction, error) {
+	tx := &applyTransaction{
+		conf:     conf,
+		previous: map[string]*types.Service{},
+		out:      out,
+	}
+	for _, target := range targets {
+		current, err := service.GetService(conf.Oscar[target.cluster], target.svc.Name)
+		if err != nil {
+			if !errors.Is(err, cluster.ErrNotFound) {
+				return nil, fmt.Errorf("unable to snapshot the service \"%s\" in cluster \"%s\", no changes have been made: %w", target.svc.Name, target.cluster, err)
+			}
+			current = nil
+		}
+		tx.previous[transactionKey(target)] = current
+	}
+	return tx, nil
+}
+
+func transactionKey(target fdlTarget) string {
+	return target.cluster + "/" + target.svc.Name
+}
+
+// existed returns true if the service was deployed before applying the FDL
+func (tx *applyTransaction) existed(target fdlTarget) bool {
+	return tx.previous[transactionKey(target)] != nil
+}
+
+// record marks a service as successfully applied
+func (tx *applyTransaction) record(target fdlTarget) {
+	tx.applied = append(tx.applied, target)
+}
+
+// rollback undoes the applied services in reverse order, restoring edited services and deleting the created ones
+func (tx *applyTransaction) rollback(cause error) error {
+	if len(tx.applied) == 0 {
+		return cause
+	}
+
+	fmt.Fprintln(tx.out, "Rolling back the applied services...")
+
+	failed := 0
+	for i := len(tx.applied) - 1; i >= 0; i-- {
+		target := tx.applied[i]
+		previous := tx.previous[transactionKey(target)]
+		c := tx.conf.Oscar[target.cluster]
+
+		var msg string
+		if previous != nil {
+			msg = fmt.Sprintf(" Restoring service \"%s\" in cluster \"%s\"", target.svc.Name, target.cluster)
+		} else {
+			msg = fmt.Sprintf(" Removing service \"%s\" in cluster \"%s\"", target.svc.Name, target.cluster)
+		}
+
+		var err error
+		if previous != nil {
+			err = service.ApplyService(previous, c, http.MethodPut)
+		} else {
+			err = service.RemoveService(c, target.svc.Name)
+		}
+		// Report every step, even when the output is not a terminal
+		if err != nil {
+			failed++
+			fmt.Fprintf(tx.out, "%s%s: %v\n", failureString, msg, err)
+			continue
+		}
+		fmt.Fprintf(tx.out, "%s%s\n", successString, msg)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w\nunable to roll back %d of the %d applied services, please check them manually", cause, failed, len(tx.applied))
+	}
+	return fmt.Errorf("%w\nthe applied services have been rolled back", cause)
+}
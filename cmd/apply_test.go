@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/grycap/oscar/v3/pkg/types"
 )
 
@@ -60,3 +66,491 @@ func TestReplacePathBucket(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyCommandDryRun(t *testing.T) {
+	const clusterName = "plan-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %[1]s:
+        name: existing
+        image: ghcr.io/demo/app:2.0
+        memory: 256Mi
+        script: existing.sh
+    - %[1]s:
+        name: fresh
+        image: ghcr.io/demo/fresh:latest
+        script: fresh.sh
+`, clusterName), "existing.sh", "fresh.sh")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/existing":
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(&types.Service{
+				Name:   "existing",
+				Image:  "ghcr.io/demo/app:1.0",
+				Memory: "256Mi",
+				Script: "#!/bin/bash\necho existing.sh\n",
+			}); err != nil {
+				t.Fatalf("encoding service: %v", err)
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/fresh":
+			http.NotFound(w, r)
+		default:
+			t.Fatalf("unexpected request during dry run: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	stdout, _, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--dry-run")
+	if err != nil {
+		t.Fatalf("apply --dry-run returned error: %v", err)
+	}
+
+	expected := []string{
+		`service "existing" will be changed in cluster "plan-cluster"`,
+		`~ image: "ghcr.io/demo/app:1.0" -> "ghcr.io/demo/app:2.0"`,
+		`service "fresh" will be created in cluster "plan-cluster"`,
+		"Plan: 1 to create, 1 to change, 0 unchanged.",
+	}
+	for _, want := range expected {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "memory") {
+		t.Fatalf("unexpected diff for unchanged memory: %q", stdout)
+	}
+}
+
+func TestApplyCommandDryRunFailsOnClusterErrors(t *testing.T) {
+	const clusterName = "plan-error-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: fresh
+        image: ghcr.io/demo/fresh:latest
+        script: fresh.sh
+`, clusterName), "fresh.sh")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/fresh":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		default:
+			t.Fatalf("unexpected request during dry run: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	stdout, _, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--dry-run")
+	if err == nil || !strings.Contains(err.Error(), "invalid credentials") {
+		t.Fatalf("expected invalid credentials error, got %v", err)
+	}
+	if strings.Contains(stdout, "will be created") {
+		t.Fatalf("unexpected plan for an unreachable service: %q", stdout)
+	}
+}
+
+func TestApplyCommandPrune(t *testing.T) {
+	const clusterName = "prune-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: keep
+        image: ghcr.io/demo/keep:latest
+        script: keep.sh
+`, clusterName), "keep.sh")
+	fdlID := service.FDLIdentifier(fdlPath, "", "")
+
+	var (
+		appliedLabels map[string]string
+		deleted       []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/keep":
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			var svc types.Service
+			if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+				t.Fatalf("decoding applied service: %v", err)
+			}
+			appliedLabels = svc.Labels
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services":
+			w.Header().Set("Content-Type", "application/json")
+			list := []*types.Service{
+				{Name: "keep", Labels: map[string]string{"oscar-cli/fdl": fdlID}},
+				{Name: "old", Labels: map[string]string{"oscar-cli/fdl": fdlID}},
+				{Name: "foreign", Labels: map[string]string{"oscar-cli/fdl": "other"}},
+				{Name: "manual"},
+			}
+			if err := json.NewEncoder(w).Encode(list); err != nil {
+				t.Fatalf("encoding services: %v", err)
+			}
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/system/services/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	stdout, _, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--prune", "--yes")
+	if err != nil {
+		t.Fatalf("apply --prune returned error: %v", err)
+	}
+
+	if got := appliedLabels["oscar-cli/fdl"]; got != fdlID {
+		t.Fatalf("expected applied service to be labelled with the FDL identifier, got %q", got)
+	}
+	if len(deleted) != 1 || deleted[0] != "old" {
+		t.Fatalf("expected only service old to be pruned, got %v", deleted)
+	}
+	if !strings.Contains(stdout, `"old" in cluster "prune-cluster"`) {
+		t.Fatalf("expected pruned service to be listed, got %q", stdout)
+	}
+}
+
+func TestApplyCommandPruneSameNamedFDLs(t *testing.T) {
+	const clusterName = "prune-same-name-cluster"
+
+	fdlA := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: a-service
+        image: ghcr.io/demo/a:latest
+        script: a.sh
+`, clusterName), "a.sh")
+	fdlB := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: b-service
+        image: ghcr.io/demo/b:latest
+        script: b.sh
+`, clusterName), "b.sh")
+
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services":
+			w.Header().Set("Content-Type", "application/json")
+			list := []*types.Service{
+				{Name: "a-service", Labels: map[string]string{service.FDLLabel: service.FDLIdentifier(fdlA, "", "")}},
+				{Name: "b-old", Labels: map[string]string{service.FDLLabel: service.FDLIdentifier(fdlB, "", "")}},
+			}
+			if err := json.NewEncoder(w).Encode(list); err != nil {
+				t.Fatalf("encoding services: %v", err)
+			}
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/system/services/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	if _, _, err := runCommand(t, "apply", fdlB, "--config", configFile, "--prune", "--yes"); err != nil {
+		t.Fatalf("apply --prune returned error: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "b-old" {
+		t.Fatalf("expected only service b-old to be pruned, got %v", deleted)
+	}
+}
+
+func TestApplyCommandPruneClusterRemovedFromFDL(t *testing.T) {
+	fdlPath := writeFDLFile(t, `
+functions:
+  oscar:
+    - cluster-1:
+        name: keep
+        image: ghcr.io/demo/keep:latest
+        script: keep.sh
+`, "keep.sh")
+	fdlID := service.FDLIdentifier(fdlPath, "", "")
+
+	newServer := func(services []*types.Service, deleted *[]string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"name":"oscar"}`)
+			case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+				http.NotFound(w, r)
+			case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+				w.WriteHeader(http.StatusCreated)
+			case r.Method == http.MethodGet && r.URL.Path == "/system/services":
+				w.Header().Set("Content-Type", "application/json")
+				if err := json.NewEncoder(w).Encode(services); err != nil {
+					t.Fatalf("encoding services: %v", err)
+				}
+			case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/system/services/"):
+				*deleted = append(*deleted, strings.TrimPrefix(r.URL.Path, "/system/services/"))
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	var deletedFirst, deletedSecond []string
+	first := newServer([]*types.Service{{Name: "keep", Labels: map[string]string{service.FDLLabel: fdlID}}}, &deletedFirst)
+	second := newServer([]*types.Service{{Name: "moved", Labels: map[string]string{service.FDLLabel: fdlID}}}, &deletedSecond)
+
+	configFile := writeMultiClusterConfig(t, first.URL, second.URL)
+
+	if _, _, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--prune", "--yes"); err != nil {
+		t.Fatalf("apply --prune returned error: %v", err)
+	}
+	if len(deletedFirst) != 0 {
+		t.Fatalf("unexpected services pruned from cluster-1: %v", deletedFirst)
+	}
+	if len(deletedSecond) != 1 || deletedSecond[0] != "moved" {
+		t.Fatalf("expected service moved to be pruned from cluster-2, got %v", deletedSecond)
+	}
+}
+
+func TestApplyCommandAtomicRollback(t *testing.T) {
+	const clusterName = "atomic-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %[1]s:
+        name: plants
+        image: ghcr.io/demo/plants:2.0
+        script: plants.sh
+    - %[1]s:
+        name: grayify
+        image: ghcr.io/demo/grayify:latest
+        script: grayify.sh
+    - %[1]s:
+        name: broken
+        image: ghcr.io/demo/broken:latest
+        script: broken.sh
+`, clusterName), "plants.sh", "grayify.sh", "broken.sh")
+
+	var requests []string
+	var restoredImage string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/plants":
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(&types.Service{Name: "plants", Image: "ghcr.io/demo/plants:1.0"}); err != nil {
+				t.Fatalf("encoding service: %v", err)
+			}
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			var svc types.Service
+			if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+				t.Fatalf("decoding service: %v", err)
+			}
+			requests = append(requests, "POST "+svc.Name)
+			if svc.Name == "broken" {
+				http.Error(w, "invalid image", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && r.URL.Path == "/system/services":
+			var svc types.Service
+			if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+				t.Fatalf("decoding service: %v", err)
+			}
+			requests = append(requests, "PUT "+svc.Name)
+			restoredImage = svc.Image
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			requests = append(requests, "DELETE "+strings.TrimPrefix(r.URL.Path, "/system/services/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	_, stderr, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--atomic")
+	if err == nil {
+		t.Fatalf("expected apply --atomic to fail")
+	}
+	if !strings.Contains(err.Error(), "invalid image") || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"PUT plants", "POST grayify", "POST broken", "DELETE grayify", "PUT plants"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected requests %v, got %v", expected, requests)
+	}
+	if restoredImage != "ghcr.io/demo/plants:1.0" {
+		t.Fatalf("expected previous image to be restored, got %q", restoredImage)
+	}
+	if !strings.Contains(stderr, `Removing service "grayify" in cluster "atomic-cluster"`) || !strings.Contains(stderr, `Restoring service "plants" in cluster "atomic-cluster"`) {
+		t.Fatalf("expected rollback steps to be reported on stderr, got %q", stderr)
+	}
+}
+
+func TestApplyCommandAtomicSnapshotFailure(t *testing.T) {
+	const clusterName = "atomic-snapshot-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %[1]s:
+        name: fresh
+        image: ghcr.io/demo/fresh:latest
+        script: fresh.sh
+    - %[1]s:
+        name: unknown
+        image: ghcr.io/demo/unknown:latest
+        script: unknown.sh
+`, clusterName), "fresh.sh", "unknown.sh")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/fresh":
+			http.NotFound(w, r)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services/unknown":
+			http.Error(w, "timeout talking to kubernetes", http.StatusInternalServerError)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	_, _, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--atomic")
+	if err == nil || !strings.Contains(err.Error(), "unable to snapshot the service \"unknown\"") {
+		t.Fatalf("expected snapshot error, got %v", err)
+	}
+}
+
+func TestApplyCommandResolvesValues(t *testing.T) {
+	const clusterName = "values-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: ${name}
+        image: ghcr.io/demo/app:{{ .Values.tag }}
+        memory: ${OSCAR_TEST_MEMORY:-512Mi}
+        script: app.sh
+`, clusterName), "app.sh")
+
+	var applied types.Service
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			if err := json.NewDecoder(r.Body).Decode(&applied); err != nil {
+				t.Fatalf("decoding service: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	_, _, err := runCommand(t, "apply", fdlPath, "--config", configFile, "--set", "name=staging-app", "--set", "tag=1.4")
+	if err != nil {
+		t.Fatalf("apply returned error: %v", err)
+	}
+
+	if applied.Name != "staging-app" || applied.Image != "ghcr.io/demo/app:1.4" || applied.Memory != "512Mi" {
+		t.Fatalf("unexpected applied service: name=%q image=%q memory=%q", applied.Name, applied.Image, applied.Memory)
+	}
+}
+
+func TestApplyCommandKeepsPlaceholdersWithoutValues(t *testing.T) {
+	const clusterName = "literal-cluster"
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: literal
+        image: "ghcr.io/demo/app:${HOME}-{{ .Values.tag }}"
+        script: app.sh
+`, clusterName), "app.sh")
+
+	var applied types.Service
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			if err := json.NewDecoder(r.Body).Decode(&applied); err != nil {
+				t.Fatalf("decoding service: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	if _, _, err := runCommand(t, "apply", fdlPath, "--config", configFile); err != nil {
+		t.Fatalf("apply returned error: %v", err)
+	}
+	if applied.Image != "ghcr.io/demo/app:${HOME}-{{ .Values.tag }}" {
+		t.Fatalf("expected the image to be applied literally, got %q", applied.Image)
+	}
+}
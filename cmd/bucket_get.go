@@ -17,18 +17,22 @@ limitations under the License.
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 	"text/tabwriter"
 
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
 func bucketGetFunc(cmd *cobra.Command, args []string) error {
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	format, err := getOutputFormat(cmd)
 	if err != nil {
 		return err
 	}
@@ -66,47 +70,45 @@ func bucketGetFunc(cmd *cobra.Command, args []string) error {
 		result.Objects = filtered
 	}
 
-	output, _ := cmd.Flags().GetString("output")
-	switch output {
-	case "json":
-		if err := bucketGetPrintJSON(cmd, result.Objects); err != nil {
-			return err
+	err = printOutput(cmd, result.Objects, func(out io.Writer, wide bool) error {
+		w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		if wide {
+			fmt.Fprintln(w, "NAME\tSIZE (B)\tLAST MODIFIED\tOWNER")
+		} else {
+			fmt.Fprintln(w, "NAME\tSIZE (B)\tLAST MODIFIED")
 		}
-	case "table":
-		bucketGetPrintTable(cmd, bucketName, result.Objects)
-	default:
-		return fmt.Errorf("unsupported output format %q", output)
-	}
+		for _, obj := range result.Objects {
+			lastModified := "-"
+			if !obj.LastModified.IsZero() {
+				lastModified = obj.LastModified.Format("2006-01-02 15:04:05")
+			}
+			if wide {
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", obj.Name, obj.Size, lastModified, obj.Owner)
+			} else {
+				fmt.Fprintf(w, "%s\t%d\t%s\n", obj.Name, obj.Size, lastModified)
+			}
+		}
+		w.Flush()
 
-	if !allPages && result.NextPage != "" {
-		fmt.Fprintf(cmd.OutOrStdout(), "\nMore objects are available. Continue listing with --page %q or fetch everything with --all.\n", result.NextPage)
+		if len(result.Objects) == 0 {
+			fmt.Fprintf(out, "Bucket %q has no objects.\n", bucketName)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-func bucketGetPrintJSON(cmd *cobra.Command, objects []*storage.BucketObject) error {
-	encoder := json.NewEncoder(cmd.OutOrStdout())
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(objects)
-}
-
-func bucketGetPrintTable(cmd *cobra.Command, bucketName string, objects []*storage.BucketObject) {
-	out := cmd.OutOrStdout()
-	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tSIZE (B)\tLAST MODIFIED")
-	for _, obj := range objects {
-		lastModified := "-"
-		if !obj.LastModified.IsZero() {
-			lastModified = obj.LastModified.Format("2006-01-02 15:04:05")
+	if !allPages && result.NextPage != "" {
+		// Keep the structured outputs parseable by printing the hint to stderr
+		hintOut := cmd.OutOrStdout()
+		if format.name != outputTable && format.name != outputWide {
+			hintOut = cmd.ErrOrStderr()
 		}
-		fmt.Fprintf(w, "%s\t%d\t%s\n", obj.Name, obj.Size, lastModified)
+		fmt.Fprintf(hintOut, "\nMore objects are available. Continue listing with --page %q or fetch everything with --all.\n", result.NextPage)
 	}
-	w.Flush()
 
-	if len(objects) == 0 {
-		fmt.Fprintf(out, "Bucket %q has no objects.\n", bucketName)
-	}
+	return nil
 }
 
 func makeBucketGetCmd() *cobra.Command {
@@ -120,7 +122,7 @@ func makeBucketGetCmd() *cobra.Command {
 	}
 
 	bucketGetCmd.Flags().StringP("cluster", "c", "", "set the cluster")
-	bucketGetCmd.Flags().StringP("output", "o", "table", "output format (table or json)")
+	addOutputFlag(bucketGetCmd, outputTable)
 	bucketGetCmd.Flags().String("prefix", "", "filter objects by key prefix")
 	bucketGetCmd.Flags().String("page", "", "continuation token returned by a previous call")
 	bucketGetCmd.Flags().Int("limit", 0, "maximum number of objects to request per call (default server limit)")
@@ -17,21 +17,34 @@ limitations under the License.
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"text/tabwriter"
 
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
 func bucketListFunc(cmd *cobra.Command, args []string) error {
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	clusterIDs, err := getClusters(cmd, conf)
+	if err != nil {
+		return err
+	}
+	if clusterIDs != nil {
+		return bucketListClusters(cmd, conf, clusterIDs)
+	}
+
 	clusterName, err := getCluster(cmd, conf)
 	if err != nil {
 		return err
@@ -42,44 +55,60 @@ func bucketListFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	output, _ := cmd.Flags().GetString("output")
-	switch output {
-	case "json":
-		if err := bucketListPrintJSON(cmd, result); err != nil {
-			return err
+	return printOutput(cmd, result, func(out io.Writer, wide bool) error {
+		w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, bucketListHeader(wide))
+		for _, obj := range result {
+			fmt.Fprintln(w, bucketListRow(obj, wide))
 		}
-	case "table":
-		bucketListPrintTable(cmd, result)
-	default:
-		return fmt.Errorf("unsupported output format %q", output)
-	}
+		w.Flush()
 
-	return nil
-}
-
-func bucketListPrintJSON(cmd *cobra.Command, objects []*storage.BucketInfo) error {
-	encoder := json.NewEncoder(cmd.OutOrStdout())
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(objects)
+		if len(result) == 0 {
+			fmt.Fprintf(out, "There is no Bucket.\n")
+		}
+		return nil
+	})
 }
 
-func bucketListPrintTable(cmd *cobra.Command, objects []*storage.BucketInfo) {
-	out := cmd.OutOrStdout()
-	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tVISIBILITY\tALLOWED USERS\tOWNER")
-	for _, obj := range objects {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", obj.Name, obj.Visibility, obj.AllowedUsers, obj.Owner)
+// bucketListClusters prints the buckets of several clusters, reporting the clusters that failed
+func bucketListClusters(cmd *cobra.Command, conf *config.Config, clusterIDs []string) error {
+	results := forEachCluster(conf, clusterIDs, func(c *cluster.Cluster) (interface{}, error) {
+		return storage.ListBuckets(c)
+	})
+
+	err := printOutput(cmd, clusterOutputs(results), func(out io.Writer, wide bool) error {
+		w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "CLUSTER\t%s\n", bucketListHeader(wide))
+		for _, r := range results {
+			if r.err != nil {
+				continue
+			}
+			for _, obj := range r.value.([]*storage.BucketInfo) {
+				fmt.Fprintf(w, "%s\t%s\n", r.cluster, bucketListRow(obj, wide))
+			}
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
 	}
-	w.Flush()
 
-	if len(objects) == 0 {
-		fmt.Fprintf(out, "There is no Bucket.\n")
+	return reportClusterErrors(cmd, results)
+}
+
+func bucketListHeader(wide bool) string {
+	if wide {
+		return "NAME\tVISIBILITY\tALLOWED USERS\tOWNER\tPROVIDER"
 	}
-	w.Flush()
+	return "NAME\tVISIBILITY\tALLOWED USERS\tOWNER"
+}
 
-	if len(objects) == 0 {
-		fmt.Fprintf(out, "There is no Bucket.\n")
+func bucketListRow(obj *storage.BucketInfo, wide bool) string {
+	row := fmt.Sprintf("%s\t%s\t%s\t%s", obj.Name, obj.Visibility, obj.AllowedUsers, obj.Owner)
+	if wide {
+		row = fmt.Sprintf("%s\t%s", row, obj.Provider)
 	}
+	return row
 }
 
 func makeBucketListCmd() *cobra.Command {
@@ -93,7 +122,8 @@ func makeBucketListCmd() *cobra.Command {
 	}
 
 	bucketListCmd.Flags().StringP("cluster", "c", "", "set the cluster")
-	bucketListCmd.Flags().StringP("output", "o", "table", "output format (table or json)")
+	addOutputFlag(bucketListCmd, outputTable)
+	addMultiClusterFlags(bucketListCmd)
 
 	return bucketListCmd
 }
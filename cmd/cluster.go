@@ -44,6 +44,9 @@ func makeClusterCmd() *cobra.Command {
 	clusterCmd.AddCommand(makeClusterInfoCmd())
 	clusterCmd.AddCommand(makeClusterListCmd())
 	clusterCmd.AddCommand(makeClusterDefaultCmd())
+	clusterCmd.AddCommand(makeClusterLoginCmd())
+	clusterCmd.AddCommand(makeClusterBackupCmd())
+	clusterCmd.AddCommand(makeClusterRestoreCmd())
 
 	return clusterCmd
 }
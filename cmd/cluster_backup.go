@@ -0,0 +1,172 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/spf13/cobra"
+)
+
+const (
+	backupManifestFile = "backup.json"
+	backupFDLFile      = "fdl.yaml"
+	backupBucketsDir   = "buckets"
+)
+
+// clusterBackup is the manifest stored in the backup directory
+type clusterBackup struct {
+	Cluster   string                `json:"cluster"`
+	CreatedAt time.Time             `json:"created_at"`
+	Services  []string              `json:"services"`
+	Buckets   []clusterBackupBucket `json:"buckets"`
+	WithData  bool                  `json:"with_data"`
+}
+
+type clusterBackupBucket struct {
+	Name         string   `json:"name"`
+	Visibility   string   `json:"visibility,omitempty"`
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+	Files        int      `json:"files,omitempty"`
+}
+
+func clusterBackupFunc(cmd *cobra.Command, args []string) error {
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	clusterName, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+	c := conf.Oscar[clusterName]
+
+	dir, _ := cmd.Flags().GetString("out")
+	withData, _ := cmd.Flags().GetBool("with-data")
+	force, _ := cmd.Flags().GetBool("force")
+
+	if _, err := os.Stat(filepath.Join(dir, backupManifestFile)); err == nil && !force {
+		return fmt.Errorf("the directory \"%s\" already contains a backup, use --force to overwrite it", dir)
+	}
+
+	manifest := clusterBackup{
+		Cluster:   clusterName,
+		CreatedAt: time.Now().UTC(),
+		Services:  []string{},
+		Buckets:   []clusterBackupBucket{},
+		WithData:  withData,
+	}
+
+	// Export the services
+	services, err := service.ListServices(c)
+	if err != nil {
+		return err
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	if len(services) > 0 {
+		fdl, scripts, err := service.ExportFDL(clusterName, services)
+		if err != nil {
+			return err
+		}
+		content, err := service.MarshalFDL(fdl)
+		if err != nil {
+			return err
+		}
+		if _, err := writeExportedFDL(filepath.Join(dir, backupFDLFile), content, scripts, true); err != nil {
+			return err
+		}
+		for _, svc := range services {
+			manifest.Services = append(manifest.Services, svc.Name)
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%sExported %d services from cluster \"%s\"\n", successString, len(services), clusterName)
+
+	// Save the buckets and, optionally, their content
+	buckets, err := storage.ListBucketsWithContext(cmd.Context(), c)
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		entry := clusterBackupBucket{
+			Name:         bucket.Name,
+			Visibility:   bucket.Visibility,
+			AllowedUsers: bucket.AllowedUsers,
+		}
+
+		if withData {
+			msg := fmt.Sprintf(" Downloading bucket \"%s\"", bucket.Name)
+
+			// Make and start the spinner
+			s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+			s.Suffix = msg
+			s.Start()
+
+			files, err := storage.DownloadBucket(cmd.Context(), c, bucket.Name, filepath.Join(dir, backupBucketsDir, bucket.Name))
+			if err != nil {
+				s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+				s.Stop()
+				return err
+			}
+			s.FinalMSG = fmt.Sprintf("%s%s (%d files)\n", successString, msg, files)
+			s.Stop()
+			entry.Files = files
+		}
+
+		manifest.Buckets = append(manifest.Buckets, entry)
+	}
+
+	// Write the manifest last, so incomplete backups are not restored
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, backupManifestFile), content, 0o644); err != nil {
+		return fmt.Errorf("writing the backup manifest: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%sBackup of cluster \"%s\" saved in \"%s\" (%d services, %d buckets)\n", successString, clusterName, dir, len(manifest.Services), len(manifest.Buckets))
+
+	return nil
+}
+
+func makeClusterBackupCmd() *cobra.Command {
+	clusterBackupCmd := &cobra.Command{
+		Use:   "backup --out DIRECTORY",
+		Short: "Back up the services and buckets of a cluster",
+		Long:  "Back up the services of a cluster as a FDL file with their scripts, along with the list of buckets and, optionally, their content.\nThe backup can be restored in any cluster with the \"cluster restore\" command.",
+		Args:  cobra.NoArgs,
+		RunE:  clusterBackupFunc,
+	}
+
+	clusterBackupCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	clusterBackupCmd.Flags().String("out", "", "directory to store the backup")
+	clusterBackupCmd.Flags().Bool("with-data", false, "download the content of the buckets")
+	clusterBackupCmd.Flags().BoolP("force", "f", false, "overwrite an existing backup in the directory")
+	clusterBackupCmd.MarkFlagRequired("out")
+
+	return clusterBackupCmd
+}
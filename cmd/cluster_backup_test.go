@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func TestClusterBackupAndRestore(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]*types.Service{
+				{
+					Name:     "demo",
+					Image:    "img:1",
+					Script:   "echo demo",
+					Token:    "secret-token",
+					Clusters: map[string]types.Cluster{"prod": {Endpoint: "https://prod.example.com"}},
+					StorageProviders: &types.StorageProviders{MinIO: map[string]*types.MinIOProvider{
+						"default": {Endpoint: "https://minio.prod.example.com"},
+					}},
+				},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/system/buckets":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"buckets":[{"bucket_name":"demo"},{"bucket_name":"manual","visibility":"restricted","allowed_users":["alice"]}]}`)
+		default:
+			t.Errorf("unexpected request to source: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer source.Close()
+
+	var (
+		mu             sync.Mutex
+		restored       []types.Service
+		createdBuckets []map[string]interface{}
+	)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"recovery","minio_provider":{"endpoint":"https://minio.recovery.example.com","region":"us-east-1","access_key":"key","secret_key":"secret","verify":true}}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			var svc types.Service
+			if err := json.NewDecoder(r.Body).Decode(&svc); err != nil {
+				t.Errorf("decoding service: %v", err)
+			}
+			restored = append(restored, svc)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/buckets":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"buckets":[{"bucket_name":"demo"}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/buckets":
+			var bucket map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&bucket); err != nil {
+				t.Errorf("decoding bucket: %v", err)
+			}
+			createdBuckets = append(createdBuckets, bucket)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request to target: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer target.Close()
+
+	configFile := writeRawConfig(t, fmt.Sprintf(`oscar:
+  prod:
+    endpoint: "%s"
+    auth_user: "user"
+    auth_password: "pass"
+    ssl_verify: false
+  recovery:
+    endpoint: "%s"
+    auth_user: "user"
+    auth_password: "pass"
+    ssl_verify: false
+default: prod
+`, source.URL, target.URL))
+	backupDir := filepath.Join(t.TempDir(), "backup")
+
+	stdout, _, err := runCommand(t, "cluster", "backup", "-c", "prod", "--out", backupDir, "--config", configFile)
+	if err != nil {
+		t.Fatalf("backup returned error: %v", err)
+	}
+	if !strings.Contains(stdout, "(1 services, 2 buckets)") {
+		t.Fatalf("unexpected backup output: %q", stdout)
+	}
+	for _, name := range []string{backupManifestFile, backupFDLFile, "demo.sh"} {
+		if _, err := os.Stat(filepath.Join(backupDir, name)); err != nil {
+			t.Fatalf("expected %s in the backup: %v", name, err)
+		}
+	}
+
+	if _, _, err := runCommand(t, "cluster", "backup", "-c", "prod", "--out", backupDir, "--config", configFile); err == nil {
+		t.Fatalf("expected an error when overwriting a backup without --force")
+	}
+
+	if _, _, err := runCommand(t, "cluster", "restore", backupDir, "-c", "recovery", "--config", configFile); err != nil {
+		t.Fatalf("restore returned error: %v", err)
+	}
+
+	if len(restored) != 1 || restored[0].Name != "demo" || restored[0].Script != "echo demo" || restored[0].Token != "" || restored[0].ClusterID != "recovery" {
+		t.Fatalf("unexpected restored services: %+v", restored)
+	}
+	// The restored service points to the target cluster and its MinIO, not to the ones of the backed up cluster
+	if c, ok := restored[0].Clusters["recovery"]; !ok || c.Endpoint != target.URL || len(restored[0].Clusters) != 1 {
+		t.Fatalf("expected the service to reference only the target cluster, got %+v", restored[0].Clusters)
+	}
+	if restored[0].StorageProviders == nil || restored[0].StorageProviders.MinIO["recovery"] == nil ||
+		restored[0].StorageProviders.MinIO["recovery"].Endpoint != "https://minio.recovery.example.com" {
+		t.Fatalf("expected the MinIO provider of the target cluster, got %+v", restored[0].StorageProviders)
+	}
+	if _, ok := restored[0].StorageProviders.MinIO["default"]; ok {
+		t.Fatalf("unexpected MinIO provider of the backed up cluster")
+	}
+	if len(createdBuckets) != 1 || createdBuckets[0]["bucket_name"] != "manual" || createdBuckets[0]["visibility"] != "restricted" {
+		t.Fatalf("unexpected created buckets: %+v", createdBuckets)
+	}
+}
@@ -17,19 +17,29 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
-
-	"github.com/goccy/go-yaml"
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
 
 func clusterInfoFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	clusterIDs, err := getClusters(cmd, conf)
 	if err != nil {
 		return err
 	}
+	if clusterIDs != nil {
+		return clusterInfoClusters(cmd, conf, clusterIDs)
+	}
 
 	cluster, err := getCluster(cmd, conf)
 	if err != nil {
@@ -41,10 +51,20 @@ func clusterInfoFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	yaml, _ := yaml.Marshal(info)
-	fmt.Print(string(yaml))
+	return printOutput(cmd, info, nil)
+}
+
+// clusterInfoClusters prints the information of several clusters, reporting the clusters that failed
+func clusterInfoClusters(cmd *cobra.Command, conf *config.Config, clusterIDs []string) error {
+	results := forEachCluster(conf, clusterIDs, func(c *cluster.Cluster) (interface{}, error) {
+		return c.GetClusterInfo()
+	})
+
+	if err := printOutput(cmd, clusterOutputs(results), nil); err != nil {
+		return err
+	}
 
-	return nil
+	return reportClusterErrors(cmd, results)
 }
 
 func makeClusterInfoCmd() *cobra.Command {
@@ -57,6 +77,8 @@ func makeClusterInfoCmd() *cobra.Command {
 	}
 
 	clusterInfoCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	addMultiClusterFlags(clusterInfoCmd)
+	addOutputFlag(clusterInfoCmd, outputYAML)
 
 	return clusterInfoCmd
 }
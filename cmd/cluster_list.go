@@ -18,39 +18,76 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
 
+// clusterListEntry is the structured output of a configured cluster, credentials are never included
+type clusterListEntry struct {
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint"`
+	AuthUser  string `json:"auth_user,omitempty"`
+	OIDC      bool   `json:"oidc"`
+	SSLVerify bool   `json:"ssl_verify"`
+	Default   bool   `json:"default"`
+}
+
 func clusterListFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
-	if len(conf.Oscar) == 0 {
-		fmt.Println("There are no defined clusters in the config file")
-	} else {
-		def := conf.Default
+	entries := []clusterListEntry{}
+	for _, id := range conf.ClusterIDs() {
+		c := conf.Oscar[id]
+		entries = append(entries, clusterListEntry{
+			Name:      id,
+			Endpoint:  c.Endpoint,
+			AuthUser:  c.AuthUser,
+			OIDC:      c.OIDCAccountName != "" || c.OIDCRefreshToken != "",
+			SSLVerify: c.SSLVerify,
+			Default:   id == conf.Default,
+		})
+	}
+
+	return printOutput(cmd, entries, func(out io.Writer, wide bool) error {
+		if len(entries) == 0 {
+			fmt.Fprintln(out, "There are no defined clusters in the config file")
+			return nil
+		}
+
+		if wide {
+			w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tENDPOINT\tAUTH\tSSL VERIFY\tDEFAULT")
+			for _, e := range entries {
+				auth := "basic"
+				if e.OIDC {
+					auth = "oidc"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\n", e.Name, e.Endpoint, auth, e.SSLVerify, e.Default)
+			}
+			return w.Flush()
+		}
 
 		// Configure bold font
 		bold := color.New(color.Bold)
 
 		// Print the clusters
-		for k, v := range conf.Oscar {
-			if k == def {
+		for _, e := range entries {
+			if e.Default {
 				// Print the default bold
-				bold.Printf("%s (%s) (Default)\n", k, v.Endpoint)
+				bold.Fprintf(out, "%s (%s) (Default)\n", e.Name, e.Endpoint)
 			} else {
-				fmt.Printf("%s (%s)\n", k, v.Endpoint)
+				fmt.Fprintf(out, "%s (%s)\n", e.Name, e.Endpoint)
 			}
 		}
-	}
-
-	return nil
+		return nil
+	})
 }
 
 func makeClusterListCmd() *cobra.Command {
@@ -62,5 +99,7 @@ func makeClusterListCmd() *cobra.Command {
 		RunE:    clusterListFunc,
 	}
 
+	addOutputFlag(clusterListCmd, outputTable)
+
 	return clusterListCmd
 }
@@ -0,0 +1,159 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultLoginScope = "openid profile email offline_access"
+	// loginTimeout is the time the user has to complete the login
+	loginTimeout = 10 * time.Minute
+)
+
+// openBrowser opens a URL in the browser of the user
+var openBrowser = func(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+func clusterLoginFunc(cmd *cobra.Command, args []string) error {
+	identifier := args[0]
+
+	conf, err := config.ReadConfig(configPath)
+	if err != nil {
+		conf = &config.Config{
+			Oscar: map[string]*cluster.Cluster{},
+		}
+	}
+
+	c, exists := conf.Oscar[identifier]
+	if !exists {
+		if len(args) != 2 {
+			cmd.SilenceUsage = false
+			return fmt.Errorf("the cluster \"%s\" doesn't exist, please provide its ENDPOINT to add it", identifier)
+		}
+		disableSSL, _ := cmd.Flags().GetBool("disable-ssl")
+		c = &cluster.Cluster{SSLVerify: !disableSSL}
+	}
+	if len(args) == 2 {
+		c.Endpoint = args[1]
+	}
+
+	issuer, _ := cmd.Flags().GetString("issuer")
+	clientID, _ := cmd.Flags().GetString("client-id")
+	scope, _ := cmd.Flags().GetString("scope")
+	if issuer == "" {
+		issuer = c.OIDCIssuer
+	}
+	if clientID == "" {
+		clientID = c.OIDCClientID
+	}
+	if !cmd.Flags().Changed("scope") && c.OIDCScope != "" {
+		scope = c.OIDCScope
+	}
+	if issuer == "" || clientID == "" {
+		cmd.SilenceUsage = false
+		return errors.New("the OIDC issuer and client ID must be set with the \"--issuer\" and \"--client-id\" flags")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	discovery, err := cluster.DiscoverOIDC(client, issuer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), loginTimeout)
+	defer cancel()
+
+	var token *cluster.ResponseRefreshToken
+	if browser, _ := cmd.Flags().GetBool("browser"); browser {
+		port, _ := cmd.Flags().GetInt("callback-port")
+		token, err = cluster.BrowserLogin(ctx, client, discovery, clientID, scope, port, func(authURL string) error {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Opening the browser to log in. If it doesn't open, visit:\n\n  %s\n\n", authURL)
+			// The user can still open the URL if the browser can't be launched
+			openBrowser(authURL)
+			return nil
+		})
+	} else {
+		token, err = cluster.DeviceLogin(ctx, client, discovery, clientID, scope, func(auth *cluster.DeviceAuthorization) {
+			if auth.VerificationURIComplete != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "To log in, visit:\n\n  %s\n\nand check that the code is %s\n\n", auth.VerificationURIComplete, auth.UserCode)
+			} else {
+				fmt.Fprintf(cmd.ErrOrStderr(), "To log in, visit:\n\n  %s\n\nand enter the code %s\n\n", auth.VerificationURI, auth.UserCode)
+			}
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("unable to log in: %w", err)
+	}
+	if token.RefreshToken == "" {
+		return errors.New("the OIDC provider didn't return a refresh token, please check that the \"offline_access\" scope is allowed for the client")
+	}
+
+	// Authenticate with the new refresh token from now on
+	c.AuthUser = ""
+	c.AuthPassword = ""
+	c.OIDCAccountName = ""
+	c.OIDCRefreshToken = token.RefreshToken
+	c.OIDCIssuer = issuer
+	c.OIDCClientID = clientID
+	c.OIDCScope = scope
+	if err := conf.SetCluster(configPath, identifier, c); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Successfully logged in to cluster \"%s\"\n", identifier)
+
+	return nil
+}
+
+func makeClusterLoginCmd() *cobra.Command {
+	clusterLoginCmd := &cobra.Command{
+		Use:   "login IDENTIFIER [ENDPOINT]",
+		Short: "Log in to a cluster with OIDC and store the refresh token in the cluster configuration",
+		Long:  "Log in to a cluster with OIDC and store the refresh token in the cluster configuration.\nBy default the device authorization grant is used: visit the URL shown and enter the code from any device. With \"--browser\" the authorization code grant with PKCE is used instead, receiving the code in a local callback server.\nThe cluster is added if it doesn't exist and its ENDPOINT is provided.",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  clusterLoginFunc,
+	}
+
+	clusterLoginCmd.Flags().String("issuer", "", "URL of the OIDC issuer (defaults to the one of the cluster configuration)")
+	clusterLoginCmd.Flags().String("client-id", "", "OIDC client ID (defaults to the one of the cluster configuration)")
+	clusterLoginCmd.Flags().String("scope", defaultLoginScope, "OIDC scopes to request, must include \"offline_access\" to get a refresh token")
+	clusterLoginCmd.Flags().Bool("browser", false, "log in through the browser (authorization code grant with PKCE) instead of with a device code")
+	clusterLoginCmd.Flags().Int("callback-port", 0, "port of the local callback server of the browser login (random by default)")
+	clusterLoginCmd.Flags().Bool("disable-ssl", false, "disable verification of ssl certificates for the added cluster")
+
+	return clusterLoginCmd
+}
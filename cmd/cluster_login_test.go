@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/oscartest"
+)
+
+func TestClusterLoginDeviceCode(t *testing.T) {
+	issuer := oscartest.NewIssuer(t, "oscar-cli")
+	configFile := writeConfigFile(t, "login-cluster", "https://oscar.example.com")
+
+	stdout, stderr, err := runCommand(t,
+		"cluster", "login", "login-cluster",
+		"--config", configFile,
+		"--issuer", issuer.URL,
+		"--client-id", "oscar-cli",
+	)
+	if err != nil {
+		t.Fatalf("cluster login returned error: %v", err)
+	}
+	if !strings.Contains(stderr, "OSCAR-TEST") || !strings.Contains(stdout, "Successfully logged in") {
+		t.Fatalf("unexpected output %q, %q", stdout, stderr)
+	}
+
+	conf, err := config.ReadConfig(configFile)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	c := conf.Oscar["login-cluster"]
+	if !issuer.RefreshTokenValid(c.OIDCRefreshToken) {
+		t.Fatalf("expected a valid refresh token, got %q", c.OIDCRefreshToken)
+	}
+	if c.AuthUser != "" || c.AuthPassword != "" || c.OIDCIssuer != issuer.URL || c.OIDCClientID != "oscar-cli" {
+		t.Fatalf("unexpected cluster configuration %+v", c)
+	}
+
+	// The access tokens are obtained with the stored refresh token, which is rotated
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if _, err := c.GetClientSafe(); err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	conf, err = config.ReadConfig(configFile)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	if rotated := conf.Oscar["login-cluster"].OIDCRefreshToken; rotated == c.OIDCRefreshToken || !issuer.RefreshTokenValid(rotated) {
+		t.Fatalf("expected the rotated refresh token to be stored, got %q", rotated)
+	}
+}
+
+func TestClusterLoginBrowser(t *testing.T) {
+	issuer := oscartest.NewIssuer(t, "oscar-cli")
+	configFile := writeRawConfig(t, "oscar: {}\n")
+
+	originalOpenBrowser := openBrowser
+	t.Cleanup(func() { openBrowser = originalOpenBrowser })
+	openBrowser = func(url string) error {
+		// The issuer approves the login right away and redirects to the callback
+		res, err := http.Get(url)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	}
+
+	_, _, err := runCommand(t,
+		"cluster", "login", "new-cluster", "https://oscar.example.com",
+		"--config", configFile,
+		"--issuer", issuer.URL,
+		"--client-id", "oscar-cli",
+		"--browser",
+	)
+	if err != nil {
+		t.Fatalf("cluster login returned error: %v", err)
+	}
+
+	conf, err := config.ReadConfig(configFile)
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	c, ok := conf.Oscar["new-cluster"]
+	if !ok || c.Endpoint != "https://oscar.example.com" || !issuer.RefreshTokenValid(c.OIDCRefreshToken) {
+		t.Fatalf("unexpected cluster configuration %+v", c)
+	}
+	if conf.Default != "new-cluster" {
+		t.Fatalf("expected the new cluster to be the default, got %q", conf.Default)
+	}
+}
+
+func TestClusterLoginMissingCluster(t *testing.T) {
+	issuer := oscartest.NewIssuer(t, "oscar-cli")
+	configFile := writeConfigFile(t, "login-cluster", "https://oscar.example.com")
+
+	_, _, err := runCommand(t, "cluster", "login", "missing", "--config", configFile, "--issuer", issuer.URL, "--client-id", "oscar-cli")
+	if err == nil || !strings.Contains(err.Error(), "ENDPOINT") {
+		t.Fatalf("expected an error for the missing cluster, got %v", err)
+	}
+
+	_, _, err = runCommand(t, "cluster", "login", "login-cluster", "--config", configFile)
+	if err == nil || !strings.Contains(err.Error(), "--issuer") {
+		t.Fatalf("expected an error without issuer, got %v", err)
+	}
+}
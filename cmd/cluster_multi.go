@@ -0,0 +1,145 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// clusterResult is the outcome of a read operation executed in one of the selected clusters
+type clusterResult struct {
+	cluster string
+	value   interface{}
+	err     error
+}
+
+// clusterOutput is the structured output of a command in one of the selected clusters
+type clusterOutput struct {
+	Cluster string      `json:"cluster"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// clusterOutputs converts the results of the clusters to their structured output
+func clusterOutputs(results []clusterResult) []clusterOutput {
+	outputs := make([]clusterOutput, 0, len(results))
+	for _, r := range results {
+		output := clusterOutput{Cluster: r.cluster, Result: r.value}
+		if r.err != nil {
+			output.Result = nil
+			output.Error = r.err.Error()
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+func addMultiClusterFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("all-clusters", false, "run the command in all the clusters defined in the config file")
+	cmd.Flags().StringSlice("clusters", []string{}, "run the command in the specified clusters, multiple values can be specified by a comma-separated string")
+}
+
+// getClusters returns the clusters selected with "--all-clusters" or "--clusters" in the order defined in the
+// config file, or nil if the command must be executed in a single cluster
+func getClusters(cmd *cobra.Command, conf *config.Config) ([]string, error) {
+	all, _ := cmd.Flags().GetBool("all-clusters")
+	selected, _ := cmd.Flags().GetStringSlice("clusters")
+	if !all && len(selected) == 0 {
+		return nil, nil
+	}
+
+	if all && len(selected) > 0 {
+		cmd.SilenceUsage = false
+		return nil, errors.New("the flags \"--all-clusters\" and \"--clusters\" cannot be used together")
+	}
+	if c, _ := cmd.Flags().GetString("cluster"); c != "" {
+		cmd.SilenceUsage = false
+		return nil, errors.New("the flag \"--cluster\" cannot be used along with \"--all-clusters\" or \"--clusters\"")
+	}
+
+	if all {
+		ids := conf.ClusterIDs()
+		if len(ids) == 0 {
+			return nil, errors.New("there are no clusters defined in the config file")
+		}
+		return ids, nil
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range selected {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if err := conf.CheckCluster(id); err != nil {
+			return nil, err
+		}
+		wanted[id] = true
+	}
+	if len(wanted) == 0 {
+		cmd.SilenceUsage = false
+		return nil, errors.New("no clusters provided in \"--clusters\"")
+	}
+
+	ids := []string{}
+	for _, id := range conf.ClusterIDs() {
+		if wanted[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// forEachCluster executes fn concurrently in the clusters and returns the results in the same order
+func forEachCluster(conf *config.Config, clusterIDs []string, fn func(c *cluster.Cluster) (interface{}, error)) []clusterResult {
+	results := make([]clusterResult, len(clusterIDs))
+
+	var wg sync.WaitGroup
+	for i, id := range clusterIDs {
+		results[i].cluster = id
+		wg.Add(1)
+		go func(r *clusterResult, c *cluster.Cluster) {
+			defer wg.Done()
+			r.value, r.err = fn(c)
+		}(&results[i], conf.Oscar[id])
+	}
+	wg.Wait()
+
+	return results
+}
+
+// reportClusterErrors prints the errors of the failed clusters and returns an error if any of them failed
+func reportClusterErrors(cmd *cobra.Command, results []clusterResult) error {
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(cmd.ErrOrStderr(), "%scluster \"%s\": %v\n", failureString, r.cluster, r.err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d clusters failed", failed, len(results))
+	}
+	return nil
+}
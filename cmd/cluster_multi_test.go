@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/storage"
+)
+
+func writeMultiClusterConfig(t *testing.T, endpoints ...string) string {
+	t.Helper()
+
+	var content strings.Builder
+	content.WriteString("oscar:\n")
+	for i, endpoint := range endpoints {
+		fmt.Fprintf(&content, `  cluster-%d:
+    endpoint: "%s"
+    auth_user: "user"
+    auth_password: "pass"
+    ssl_verify: false
+    memory: 256Mi
+    log_level: INFO
+`, i+1, endpoint)
+	}
+	content.WriteString("default: cluster-1\n")
+
+	return writeRawConfig(t, content.String())
+}
+
+func newMultiClusterServer(t *testing.T, services, buckets string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/services":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, services)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/buckets":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, buckets)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceListAllClustersReportsFailures(t *testing.T) {
+	first := newMultiClusterServer(t, `[{"name":"svc-a","image":"img:a"}]`, `[]`)
+	second := newMultiClusterServer(t, `[{"name":"svc-b","image":"img:b"}]`, `[]`)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+
+	configFile := writeMultiClusterConfig(t, first.URL, failing.URL, second.URL)
+
+	stdout, stderr, err := runCommand(t,
+		"service", "--config", configFile,
+		"list",
+		"--all-clusters",
+	)
+	if err == nil || err.Error() != "1 of 3 clusters failed" {
+		t.Fatalf("expected partial failure error, got %v", err)
+	}
+	if !strings.Contains(stdout, "CLUSTER") {
+		t.Fatalf("expected CLUSTER column, got %q", stdout)
+	}
+	firstRow, secondRow := strings.Index(stdout, "cluster-1"), strings.Index(stdout, "cluster-3")
+	if firstRow < 0 || secondRow < firstRow || !strings.Contains(stdout, "svc-a") || !strings.Contains(stdout, "svc-b") {
+		t.Fatalf("unexpected list output: %q", stdout)
+	}
+	if !strings.Contains(stderr, `cluster "cluster-2": invalid credentials`) {
+		t.Fatalf("expected failure of cluster-2 in stderr, got %q", stderr)
+	}
+}
+
+func TestBucketListClustersJSONOutput(t *testing.T) {
+	first := newMultiClusterServer(t, `[]`, `[{"bucket_name":"foo","visibility":"private","owner":"owner1"}]`)
+	second := newMultiClusterServer(t, `[]`, `[{"bucket_name":"bar","visibility":"public","owner":"owner2"}]`)
+	third := newMultiClusterServer(t, `[]`, `[{"bucket_name":"baz","visibility":"public","owner":"owner3"}]`)
+
+	configFile := writeMultiClusterConfig(t, first.URL, second.URL, third.URL)
+
+	stdout, _, err := runCommand(t,
+		"bucket", "--config", configFile,
+		"list",
+		"--clusters", "cluster-3,cluster-1",
+		"--output", "json",
+	)
+	if err != nil {
+		t.Fatalf("bucket list returned error: %v", err)
+	}
+
+	var results []struct {
+		Cluster string               `json:"cluster"`
+		Result  []storage.BucketInfo `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("invalid json output: %v", err)
+	}
+	if len(results) != 2 || results[0].Cluster != "cluster-1" || results[1].Cluster != "cluster-3" {
+		t.Fatalf("unexpected clusters in json output: %v", results)
+	}
+	if len(results[1].Result) != 1 || results[1].Result[0].Name != "baz" {
+		t.Fatalf("unexpected buckets of cluster-3: %v", results[1].Result)
+	}
+}
+
+func TestMultiClusterFlagsConflict(t *testing.T) {
+	server := newMultiClusterServer(t, `[]`, `[]`)
+	configFile := writeMultiClusterConfig(t, server.URL)
+
+	_, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"list",
+		"--all-clusters",
+		"--cluster", "cluster-1",
+	)
+	if err == nil || !strings.Contains(err.Error(), "cannot be used along with") {
+		t.Fatalf("expected flags conflict error, got %v", err)
+	}
+
+	_, _, err = runCommand(t,
+		"service", "--config", configFile,
+		"list",
+		"--clusters", "unknown",
+	)
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("expected unknown cluster error, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func clusterRestoreFunc(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+
+	content, err := os.ReadFile(filepath.Join(dir, backupManifestFile))
+	if err != nil {
+		return fmt.Errorf("the directory \"%s\" doesn't contain a cluster backup", dir)
+	}
+	manifest := clusterBackup{}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return errors.New("the backup manifest is not valid")
+	}
+
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	clusterName, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+	c := conf.Oscar[clusterName]
+
+	// Restore the services first, as they create their own buckets
+	if len(manifest.Services) > 0 {
+		fdl, err := service.ReadFDL(filepath.Join(dir, backupFDLFile))
+		if err != nil {
+			return err
+		}
+
+		// Rewrite the services for the target cluster as "service copy" does, as it can be a different one
+		clusterInfo, err := c.GetClusterConfig()
+		if err != nil {
+			return err
+		}
+
+		for _, element := range fdl.Functions.Oscar {
+			for _, backedUp := range element {
+				single := &service.FDL{}
+				single.Functions.Oscar = []map[string]*types.Service{{clusterName: backedUp}}
+				svc, err := buildServiceFromFDL(single, clusterName, c, clusterInfo.MinIOProvider)
+				if err != nil {
+					return err
+				}
+
+				msg := fmt.Sprintf(" Restoring service \"%s\" in cluster \"%s\"", svc.Name, clusterName)
+				method := http.MethodPost
+				exists, err := serviceExists(svc, c)
+				if err != nil {
+					return err
+				}
+				if exists {
+					method = http.MethodPut
+				}
+
+				// Make and start the spinner
+				s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+				s.Suffix = msg
+				s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
+				s.Start()
+
+				if err := service.ApplyService(svc, c, method); err != nil {
+					s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+					s.Stop()
+					return err
+				}
+				s.Stop()
+			}
+		}
+	}
+
+	// Create the remaining buckets and upload their content
+	existing, err := storage.ListBucketsWithContext(cmd.Context(), c)
+	if err != nil {
+		return err
+	}
+	existingNames := map[string]bool{}
+	for _, bucket := range existing {
+		existingNames[bucket.Name] = true
+	}
+
+	for _, bucket := range manifest.Buckets {
+		if !existingNames[bucket.Name] {
+			msg := fmt.Sprintf(" Creating bucket \"%s\" in cluster \"%s\"", bucket.Name, clusterName)
+
+			// Make and start the spinner
+			s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+			s.Suffix = msg
+			s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
+			s.Start()
+
+			info := &storage.BucketInfo{Name: bucket.Name, Visibility: bucket.Visibility, AllowedUsers: bucket.AllowedUsers}
+			if err := storage.CreateBucket(c, info); err != nil {
+				s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+				s.Stop()
+				return err
+			}
+			s.Stop()
+		}
+
+		if !manifest.WithData {
+			continue
+		}
+		bucketDir := filepath.Join(dir, backupBucketsDir, bucket.Name)
+		if _, err := os.Stat(bucketDir); err != nil {
+			continue
+		}
+
+		msg := fmt.Sprintf(" Uploading data of bucket \"%s\"", bucket.Name)
+
+		// Make and start the spinner
+		s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+		s.Suffix = msg
+		s.Start()
+
+		files, err := storage.UploadBucket(cmd.Context(), c, bucket.Name, bucketDir)
+		if err != nil {
+			s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+			s.Stop()
+			return err
+		}
+		s.FinalMSG = fmt.Sprintf("%s%s (%d files)\n", successString, msg, files)
+		s.Stop()
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%sBackup of cluster \"%s\" restored in cluster \"%s\" (%d services, %d buckets)\n", successString, manifest.Cluster, clusterName, len(manifest.Services), len(manifest.Buckets))
+
+	return nil
+}
+
+func makeClusterRestoreCmd() *cobra.Command {
+	clusterRestoreCmd := &cobra.Command{
+		Use:   "restore DIRECTORY",
+		Short: "Restore a backup of services and buckets in a cluster",
+		Long:  "Restore in a cluster the services and buckets saved with the \"cluster backup\" command.\nExisting services are updated and the content of the buckets is uploaded if it was included in the backup.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  clusterRestoreFunc,
+	}
+
+	clusterRestoreCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+
+	return clusterRestoreCmd
+}
@@ -22,7 +22,6 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/grycap/oscar/v3/pkg/types"
 	"github.com/spf13/cobra"
@@ -30,13 +29,17 @@ import (
 
 func deleteFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
 	// Read file
-	fdl, err := service.ReadFDL(args[0])
+	values, err := getFDLValues(cmd)
+	if err != nil {
+		return err
+	}
+	fdl, err := service.ReadFDLWithValues(args[0], values)
 	if err != nil {
 		return err
 	}
@@ -117,6 +120,7 @@ func makeDeleteCmd() *cobra.Command {
 
 	applyCmd.PersistentFlags().StringVar(&configPath, "config", defaultConfigPath, "set the location of the config file (YAML or JSON)")
 	applyCmd.Flags().Bool("default", false, "override the cluster id defined in config file")
+	addFDLValuesFlags(applyCmd)
 
 	return applyCmd
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/oscartest"
+)
+
+func TestApplyPutFileGetFileEndToEnd(t *testing.T) {
+	const clusterName = "fake-cluster"
+
+	server := oscartest.NewServer(t, oscartest.WithCredentials("user", "pass"))
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	fdlPath := writeFDLFile(t, fmt.Sprintf(`
+functions:
+  oscar:
+    - %s:
+        name: demo
+        image: ghcr.io/demo/app:1.0
+        memory: 256Mi
+        script: demo.sh
+        input:
+          - storage_provider: minio.default
+            path: demo/in
+        output:
+          - storage_provider: minio.default
+            path: demo/out
+`, clusterName), "demo.sh")
+
+	if _, _, err := runCommand(t, "apply", fdlPath, "--config", configFile); err != nil {
+		t.Fatalf("apply returned error: %v", err)
+	}
+	if _, ok := server.Service("demo"); !ok {
+		t.Fatalf("expected the service to be created")
+	}
+
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(localPath, []byte("some input"), 0o600); err != nil {
+		t.Fatalf("writing input: %v", err)
+	}
+	if _, _, err := runCommand(t, "service", "put-file", "demo", "minio.default", localPath, "demo/in/input.txt", "--config", configFile, "--no-progress"); err != nil {
+		t.Fatalf("put-file returned error: %v", err)
+	}
+	if data, ok := server.Object("demo", "in/input.txt"); !ok || string(data) != "some input" {
+		t.Fatalf("unexpected uploaded object %q (found %v)", data, ok)
+	}
+
+	server.PutObject("demo", "out/input.txt", []byte("some output"))
+	downloadPath := filepath.Join(dir, "output.txt")
+	if _, _, err := runCommand(t, "service", "get-file", "demo", "minio.default", "demo/out/input.txt", downloadPath, "--config", configFile, "--no-progress"); err != nil {
+		t.Fatalf("get-file returned error: %v", err)
+	}
+	if data, err := os.ReadFile(downloadPath); err != nil || string(data) != "some output" {
+		t.Fatalf("unexpected downloaded content %q, %v", data, err)
+	}
+}
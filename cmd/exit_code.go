@@ -0,0 +1,57 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+)
+
+// Exit codes of the CLI for each class of error, so scripts can react to them
+const (
+	exitCodeError        = 1
+	exitCodeNotFound     = 3
+	exitCodeUnauthorized = 4
+	exitCodeForbidden    = 5
+	exitCodeConflict     = 6
+	exitCodeUnavailable  = 7
+	exitCodeAPIError     = 8
+)
+
+// exitCode returns the exit code of the CLI for an error returned by a command
+func exitCode(err error) int {
+	var apiErr *cluster.APIError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, cluster.ErrNotFound):
+		return exitCodeNotFound
+	case errors.Is(err, cluster.ErrUnauthorized), errors.Is(err, cluster.ErrRefreshTokenExpired):
+		return exitCodeUnauthorized
+	case errors.Is(err, cluster.ErrForbidden):
+		return exitCodeForbidden
+	case errors.Is(err, cluster.ErrConflict):
+		return exitCodeConflict
+	case errors.Is(err, cluster.ErrSendingRequest), errors.Is(err, cluster.ErrServiceNotReady):
+		return exitCodeUnavailable
+	case errors.As(err, &apiErr):
+		return exitCodeAPIError
+	default:
+		return exitCodeError
+	}
+}
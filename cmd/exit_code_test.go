@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		status   int
+		expected int
+	}{
+		{http.StatusNotFound, exitCodeNotFound},
+		{http.StatusUnauthorized, exitCodeUnauthorized},
+		{http.StatusForbidden, exitCodeForbidden},
+		{http.StatusConflict, exitCodeConflict},
+		{http.StatusBadGateway, exitCodeUnavailable},
+		{http.StatusInternalServerError, exitCodeAPIError},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+			configFile := writeConfigFile(t, "exit-cluster", server.URL)
+
+			_, _, err := runCommand(t, "service", "--config", configFile, "get", "demo", "--http-retries", "0")
+			if code := exitCode(err); code != tc.expected {
+				t.Fatalf("expected exit code %d, got %d (%v)", tc.expected, code, err)
+			}
+		})
+	}
+
+	if code := exitCode(fmt.Errorf("wrapped: %w", cluster.ErrSendingRequest)); code != exitCodeUnavailable {
+		t.Fatalf("expected exit code %d for connection errors, got %d", exitCodeUnavailable, code)
+	}
+	if code := exitCode(fmt.Errorf("wrapped: %w", cluster.ErrRefreshTokenExpired)); code != exitCodeUnauthorized {
+		t.Fatalf("expected exit code %d for expired refresh tokens, got %d", exitCodeUnauthorized, code)
+	}
+	if code := exitCode(errors.New("boom")); code != exitCodeError {
+		t.Fatalf("expected exit code %d for other errors, got %d", exitCodeError, code)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+func fdlGraphFunc(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if format != "dot" && format != "mermaid" {
+		return fmt.Errorf("unsupported graph format %q", format)
+	}
+
+	values, err := getFDLValues(cmd)
+	if err != nil {
+		return err
+	}
+	fdl, err := service.ReadFDLWithValues(args[0], values)
+	if err != nil {
+		return err
+	}
+
+	graph := service.BuildWorkflowGraph(fdl)
+
+	if format == "mermaid" {
+		fmt.Fprint(cmd.OutOrStdout(), graph.Mermaid())
+	} else {
+		fmt.Fprint(cmd.OutOrStdout(), graph.DOT())
+	}
+
+	// Report the findings in stderr to keep the graph output clean
+	errOut := cmd.ErrOrStderr()
+	for _, cycle := range graph.Cycles {
+		fmt.Fprintf(errOut, "warning: cycle detected between services %s\n", strings.Join(cycle, ", "))
+	}
+	for _, output := range graph.Unconsumed {
+		fmt.Fprintf(errOut, "warning: the output \"%s\" of service \"%s\" is not consumed by any service\n", output.Storage, output.Node)
+	}
+
+	return nil
+}
+
+func makeFDLGraphCmd() *cobra.Command {
+	fdlGraphCmd := &cobra.Command{
+		Use:   "graph FDL_FILE",
+		Short: "Render the workflow of a FDL file as a graph of services chained through their storage paths",
+		Args:  cobra.ExactArgs(1),
+		RunE:  fdlGraphFunc,
+	}
+
+	fdlGraphCmd.Flags().StringP("format", "f", "dot", "graph format (dot or mermaid)")
+	addFDLValuesFlags(fdlGraphCmd)
+
+	return fdlGraphCmd
+}
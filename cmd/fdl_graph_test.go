@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFDLGraphCommand(t *testing.T) {
+	fdlPath := writeFDLFile(t, `
+functions:
+  oscar:
+    - oscar-test:
+        name: plants
+        image: ghcr.io/demo/plants
+        script: plants.sh
+        input:
+          - storage_provider: minio.default
+            path: example-workflow/in
+        output:
+          - storage_provider: minio.default
+            path: example-workflow/med
+    - oscar-test:
+        name: grayify
+        image: ghcr.io/demo/grayify
+        script: grayify.sh
+        input:
+          - storage_provider: minio.default
+            path: example-workflow/med
+        output:
+          - storage_provider: minio.default
+            path: example-workflow/res
+`, "plants.sh", "grayify.sh")
+
+	stdout, stderr, err := runCommand(t, "fdl", "graph", fdlPath, "--format", "mermaid")
+	if err != nil {
+		t.Fatalf("graph returned error: %v", err)
+	}
+	if !strings.Contains(stdout, `n0 -->|"example-workflow/med"| n1`) {
+		t.Fatalf("expected plants to be linked to grayify, got:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, `the output "example-workflow/res" of service "oscar-test/grayify" is not consumed`) {
+		t.Fatalf("expected unconsumed output warning, got %q", stderr)
+	}
+
+	if _, _, err := runCommand(t, "fdl", "graph", fdlPath, "--format", "png"); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
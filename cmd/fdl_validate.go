@@ -0,0 +1,115 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+type fdlValidationReport struct {
+	File     string                    `json:"file"`
+	Valid    bool                      `json:"valid"`
+	Errors   int                       `json:"errors"`
+	Warnings int                       `json:"warnings"`
+	Issues   []service.ValidationIssue `json:"issues"`
+}
+
+func fdlValidateFunc(cmd *cobra.Command, args []string) error {
+	if output, _ := cmd.Flags().GetString("output"); output == "text" {
+		// "text" is kept as an alias of the table format
+		cmd.Flags().Set("output", outputTable)
+	}
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	values, err := getFDLValues(cmd)
+	if err != nil {
+		return err
+	}
+	opts := service.ValidationOptions{Values: values}
+
+	if skip, _ := cmd.Flags().GetBool("skip-cluster-check"); !skip {
+		conf, err := readConfig()
+		if err != nil {
+			return err
+		}
+		opts.CheckCluster = func(clusterID string) error {
+			_, err := conf.GetCluster(false, "", clusterID)
+			return err
+		}
+	}
+
+	issues, err := service.ValidateFDL(args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	report := fdlValidationReport{File: args[0], Issues: issues}
+	for _, issue := range issues {
+		if issue.Severity == service.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0
+
+	err = printOutput(cmd, report, func(out io.Writer, wide bool) error {
+		for _, issue := range issues {
+			location := args[0]
+			if issue.Line > 0 {
+				location = fmt.Sprintf("%s:%d:%d", args[0], issue.Line, issue.Column)
+			}
+			fmt.Fprintf(out, "%s: %s: %s\n", location, issue.Severity, issue.Message)
+		}
+		if report.Valid {
+			fmt.Fprintf(out, "%sThe file \"%s\" is valid (%d warnings)\n", successString, path.Base(args[0]), report.Warnings)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("the file \"%s\" is not valid: %d errors, %d warnings", path.Base(args[0]), report.Errors, report.Warnings)
+	}
+
+	return nil
+}
+
+func makeFDLValidateCmd() *cobra.Command {
+	fdlValidateCmd := &cobra.Command{
+		Use:     "validate FDL_FILE",
+		Short:   "Check a FDL file for errors without deploying it",
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"lint"},
+		RunE:    fdlValidateFunc,
+	}
+
+	addOutputFlag(fdlValidateCmd, outputTable)
+	fdlValidateCmd.Flags().Bool("skip-cluster-check", false, "do not check that the clusters of the FDL file are defined in the config file")
+	addFDLValuesFlags(fdlValidateCmd)
+
+	return fdlValidateCmd
+}
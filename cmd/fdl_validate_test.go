@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+)
+
+func TestFDLValidateCommandJSON(t *testing.T) {
+	fdlPath := writeFDLFile(t, `
+functions:
+  oscar:
+    - other-cluster:
+        name: demo
+        image: ghcr.io/demo/app:latest
+        memory: 1Gi
+        script: app.sh
+`, "app.sh")
+	configFile := writeConfigFile(t, "validate-cluster", "http://localhost")
+
+	stdout, _, err := runCommand(t, "fdl", "validate", fdlPath, "--config", configFile, "-o", "json")
+	if err == nil {
+		t.Fatalf("expected validation to fail")
+	}
+
+	var report struct {
+		Valid  bool                      `json:"valid"`
+		Errors int                       `json:"errors"`
+		Issues []service.ValidationIssue `json:"issues"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("decoding report %q: %v", stdout, err)
+	}
+	if report.Valid || report.Errors != 1 || len(report.Issues) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if issue := report.Issues[0]; !strings.Contains(issue.Message, "other-cluster") || issue.Line != 4 {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+}
+
+func TestFDLValidateCommandSkipClusterCheck(t *testing.T) {
+	fdlPath := writeFDLFile(t, `
+functions:
+  oscar:
+    - other-cluster:
+        name: demo
+        image: ghcr.io/demo/app:latest
+        script: app.sh
+`, "app.sh")
+
+	stdout, _, err := runCommand(t, "fdl", "validate", fdlPath, "--skip-cluster-check")
+	if err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+	if !strings.Contains(stdout, "is valid") {
+		t.Fatalf("expected valid message, got %q", stdout)
+	}
+}
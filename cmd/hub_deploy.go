@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/grycap/oscar-cli/pkg/cluster"
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/hub"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/grycap/oscar/v3/pkg/types"
@@ -41,7 +40,7 @@ func (o *hubDeployOptions) applyToClient() []hub.Option {
 func hubDeployFunc(cmd *cobra.Command, args []string, opts *hubDeployOptions) error {
 	slug := args[0]
 
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -53,18 +52,23 @@ func hubDeployFunc(cmd *cobra.Command, args []string, opts *hubDeployOptions) er
 
 	clusterCfg := conf.Oscar[clusterName]
 
+	values, err := getFDLValues(cmd)
+	if err != nil {
+		return err
+	}
+
 	var fdl *service.FDL
 
 	if strings.TrimSpace(opts.localPath) != "" {
 		if _, err := os.Stat(opts.localPath); err != nil {
 			return fmt.Errorf("checking local path: %w", err)
 		}
-		fdl, err = hub.LoadLocalFDL(opts.localPath, slug)
+		fdl, err = hub.LoadLocalFDLWithValues(opts.localPath, slug, values)
 		if err != nil {
 			return err
 		}
 	} else {
-		client := hub.NewClient(opts.applyToClient()...)
+		client := hub.NewClient(append(opts.applyToClient(), hub.WithFDLValues(values))...)
 		fdl, err = client.FetchFDL(cmd.Context(), slug)
 		if err != nil {
 			return err
@@ -87,7 +91,11 @@ func hubDeployFunc(cmd *cobra.Command, args []string, opts *hubDeployOptions) er
 
 	action := "Creating"
 	method := http.MethodPost
-	if serviceExists(serviceDef, clusterCfg) {
+	exists, err := serviceExists(serviceDef, clusterCfg)
+	if err != nil {
+		return err
+	}
+	if exists {
 		action = "Updating"
 		method = http.MethodPut
 	}
@@ -131,6 +139,7 @@ func makeHubDeployCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&opts.name, "name", "n", "", "override the OSCAR service name during deployment")
 	cmd.Flags().StringVar(&opts.localPath, "local-path", "", "use a local directory containing the RO-Crate metadata instead of fetching it from GitHub")
 	cmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	addFDLValuesFlags(cmd)
 
 	if flag := cmd.Flags().Lookup("api-base"); flag != nil {
 		flag.Hidden = true
@@ -1,8 +1,8 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"text/tabwriter"
 
 	"github.com/grycap/oscar-cli/pkg/hub"
@@ -32,6 +32,13 @@ func (o *hubListOptions) applyToClient() []hub.Option {
 }
 
 func hubListFunc(cmd *cobra.Command, _ []string, opts *hubListOptions) error {
+	if opts.outputJSON {
+		cmd.Flags().Set("output", outputJSON)
+	}
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
 	client := hub.NewClient(opts.applyToClient()...)
 
 	result, err := client.ListServices(cmd.Context())
@@ -39,36 +46,39 @@ func hubListFunc(cmd *cobra.Command, _ []string, opts *hubListOptions) error {
 		return err
 	}
 
-	if opts.outputJSON {
-		payload := struct {
-			Services []hub.Service `json:"services"`
-			Warnings []hub.Warning `json:"warnings,omitempty"`
-		}{
-			Services: result.Services,
-			Warnings: result.Warnings,
-		}
-
-		encoder := json.NewEncoder(cmd.OutOrStdout())
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(payload)
+	payload := struct {
+		Services []hub.Service `json:"services"`
+		Warnings []hub.Warning `json:"warnings,omitempty"`
+	}{
+		Services: result.Services,
+		Warnings: result.Warnings,
 	}
 
-	if len(result.Services) == 0 {
-		fmt.Fprintln(cmd.OutOrStdout(), "No curated services found")
-	} else {
-		out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, ' ', 0)
-		fmt.Fprintln(out, "SLUG\tNAME\tCREATOR")
-		for _, svc := range result.Services {
-			fmt.Fprintf(out, "%s\t%s\t%s\n", svc.Slug, svc.Name, svc.Creator)
+	return printOutput(cmd, payload, func(out io.Writer, wide bool) error {
+		if len(result.Services) == 0 {
+			fmt.Fprintln(out, "No curated services found")
+		} else {
+			w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+			if wide {
+				fmt.Fprintln(w, "SLUG\tNAME\tCREATOR\tLICENSE\tURL")
+			} else {
+				fmt.Fprintln(w, "SLUG\tNAME\tCREATOR")
+			}
+			for _, svc := range result.Services {
+				if wide {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", svc.Slug, svc.Name, svc.Creator, svc.License, svc.URL)
+				} else {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", svc.Slug, svc.Name, svc.Creator)
+				}
+			}
+			w.Flush()
 		}
-		out.Flush()
-	}
 
-	for _, warning := range result.Warnings {
-		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", warning.Path, warning.Err)
-	}
-
-	return nil
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s: %v\n", warning.Path, warning.Err)
+		}
+		return nil
+	})
 }
 
 func makeHubListCmd() *cobra.Command {
@@ -92,7 +102,8 @@ func makeHubListCmd() *cobra.Command {
 	cmd.Flags().StringVar(&opts.repo, "repo", opts.repo, "GitHub repository that hosts the curated services")
 	cmd.Flags().StringVar(&opts.rootPath, "path", opts.rootPath, "subdirectory inside the repository that contains the services")
 	cmd.Flags().StringVar(&opts.ref, "ref", opts.ref, "Git reference (branch, tag, or commit) to query")
-	cmd.Flags().BoolVar(&opts.outputJSON, "json", false, "print the list in JSON format")
+	addOutputFlag(cmd, outputTable)
+	cmd.Flags().BoolVar(&opts.outputJSON, "json", false, "print the list in JSON format (same as \"--output json\")")
 	cmd.Flags().StringVar(&opts.apiBase, "api-base", "", "override the GitHub API base URL")
 	if flag := cmd.Flags().Lookup("api-base"); flag != nil {
 		flag.Hidden = true
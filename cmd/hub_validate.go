@@ -21,7 +21,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/hub"
 	"github.com/spf13/cobra"
 )
@@ -50,7 +49,7 @@ func (o *hubValidateOptions) applyToClient() []hub.Option {
 }
 
 func hubValidateFunc(cmd *cobra.Command, args []string, opts *hubValidateOptions) error {
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
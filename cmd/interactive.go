@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/tui"
 	"github.com/spf13/cobra"
 )
@@ -13,7 +12,7 @@ func makeInteractiveCmd() *cobra.Command {
 		Aliases: []string{"ui"},
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			conf, err := config.ReadConfig(configPath)
+			conf, err := readConfig()
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,197 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	outputTable          = "table"
+	outputWide           = "wide"
+	outputJSON           = "json"
+	outputYAML           = "yaml"
+	outputJSONPath       = "jsonpath"
+	outputGoTemplate     = "go-template"
+	outputFormatsSummary = "table, wide, json, yaml, jsonpath=TEMPLATE or go-template=TEMPLATE"
+)
+
+// outputFormat is the format selected with the "--output" flag
+type outputFormat struct {
+	name     string
+	template string
+}
+
+// tablePrinter writes the human readable representation of the output, wide adds additional columns
+type tablePrinter func(w io.Writer, wide bool) error
+
+func addOutputFlag(cmd *cobra.Command, defaultFormat string) {
+	cmd.Flags().StringP("output", "o", defaultFormat, fmt.Sprintf("output format (%s)", outputFormatsSummary))
+}
+
+// getOutputFormat parses the value of the "--output" flag
+func getOutputFormat(cmd *cobra.Command) (outputFormat, error) {
+	value, _ := cmd.Flags().GetString("output")
+	name, tmpl, hasTemplate := strings.Cut(value, "=")
+
+	switch name {
+	case outputTable, outputWide, outputJSON, outputYAML:
+		if !hasTemplate {
+			return outputFormat{name: name}, nil
+		}
+	case outputJSONPath, outputGoTemplate:
+		if strings.TrimSpace(tmpl) == "" {
+			return outputFormat{}, fmt.Errorf("the output format %q requires a template, e.g. \"%s=...\"", name, name)
+		}
+		return outputFormat{name: name, template: tmpl}, nil
+	}
+
+	return outputFormat{}, fmt.Errorf("unsupported output format %q, it must be one of: %s", value, outputFormatsSummary)
+}
+
+// printOutput writes the data in the format selected with the "--output" flag. Structured formats are based on
+// the JSON representation of the data, so jsonpath and go-template expressions use the JSON field names.
+// If printTable is nil the table formats fall back to YAML
+func printOutput(cmd *cobra.Command, data interface{}, printTable tablePrinter) error {
+	format, err := getOutputFormat(cmd)
+	if err != nil {
+		return err
+	}
+	return format.print(cmd.OutOrStdout(), data, printTable)
+}
+
+func (format outputFormat) print(w io.Writer, data interface{}, printTable tablePrinter) error {
+	if (format.name == outputTable || format.name == outputWide) && printTable != nil {
+		return printTable(w, format.name == outputWide)
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	switch format.name {
+	case outputJSON:
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, content, "", "  "); err != nil {
+			return err
+		}
+		indented.WriteByte('\n')
+		_, err = indented.WriteTo(w)
+		return err
+	case outputJSONPath, outputGoTemplate:
+		var generic interface{}
+		if err := json.Unmarshal(content, &generic); err != nil {
+			return err
+		}
+		if format.name == outputJSONPath {
+			return executeJSONPath(w, format.template, generic)
+		}
+		return executeGoTemplate(w, format.template, generic)
+	default:
+		ordered, err := orderedJSONValue(json.NewDecoder(bytes.NewReader(content)))
+		if err != nil {
+			return err
+		}
+		out, err := yaml.Marshal(ordered)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	}
+}
+
+func executeJSONPath(w io.Writer, expression string, data interface{}) error {
+	parser := jsonpath.New("output")
+	if err := parser.Parse(expression); err != nil {
+		return fmt.Errorf("the jsonpath expression %q is not valid: %v", expression, err)
+	}
+	if err := parser.Execute(w, data); err != nil {
+		return fmt.Errorf("unable to execute the jsonpath expression %q: %v", expression, err)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+func executeGoTemplate(w io.Writer, text string, data interface{}) error {
+	tmpl, err := template.New("output").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("the go-template %q is not valid: %v", text, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("unable to execute the go-template %q: %v", text, err)
+	}
+	fmt.Fprintln(w)
+	return nil
+}
+
+// orderedJSONValue decodes the next JSON value keeping the order of the object keys, so the YAML output
+// follows the order of the struct fields
+func orderedJSONValue(decoder *json.Decoder) (interface{}, error) {
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			object := yaml.MapSlice{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := orderedJSONValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object = append(object, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err = decoder.Token()
+			return object, err
+		}
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := orderedJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	default:
+		return token, nil
+	}
+}
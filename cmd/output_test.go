@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type outputTestItem struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+	CPU   string `json:"cpu,omitempty"`
+}
+
+func TestOutputFormats(t *testing.T) {
+	data := []outputTestItem{
+		{Name: "svc-b", Image: "img:b", CPU: "1"},
+		{Name: "svc-a", Image: "img:a"},
+	}
+	table := func(w *bytes.Buffer) tablePrinter {
+		return func(_ io.Writer, wide bool) error {
+			if wide {
+				w.WriteString("wide")
+			} else {
+				w.WriteString("table")
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		format   outputFormat
+		expected string
+	}{
+		{outputFormat{name: outputTable}, "table"},
+		{outputFormat{name: outputWide}, "wide"},
+		{outputFormat{name: outputJSON}, "[\n  {\n    \"name\": \"svc-b\",\n    \"image\": \"img:b\",\n    \"cpu\": \"1\"\n  },\n  {\n    \"name\": \"svc-a\",\n    \"image\": \"img:a\"\n  }\n]\n"},
+		{outputFormat{name: outputYAML}, "- name: svc-b\n  image: img:b\n  cpu: \"1\"\n- name: svc-a\n  image: img:a\n"},
+		{outputFormat{name: outputJSONPath, template: "{[*].name}"}, "svc-b svc-a\n"},
+		{outputFormat{name: outputGoTemplate, template: "{{range .}}{{.name}}={{.image}};{{end}}"}, "svc-b=img:b;svc-a=img:a;\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := tt.format.print(&out, data, table(&out)); err != nil {
+			t.Fatalf("printing %s output: %v", tt.format.name, err)
+		}
+		if out.String() != tt.expected {
+			t.Fatalf("unexpected %s output:\n%q\nexpected:\n%q", tt.format.name, out.String(), tt.expected)
+		}
+	}
+}
+
+func TestOutputFormatTableFallsBackToYAML(t *testing.T) {
+	var out bytes.Buffer
+	if err := (outputFormat{name: outputTable}).print(&out, outputTestItem{Name: "svc"}, nil); err != nil {
+		t.Fatalf("printing output: %v", err)
+	}
+	if out.String() != "name: svc\nimage: \"\"\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
+
+func TestServiceListJSONPathOutput(t *testing.T) {
+	const clusterName = "output-cluster"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name":"svc-a","image":"img:a"},{"name":"svc-b","image":"img:b"}]`))
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"list",
+		"-o", "jsonpath={[*].name}",
+	)
+	if err != nil {
+		t.Fatalf("service list returned error: %v", err)
+	}
+	if stdout != "svc-a svc-b\n" {
+		t.Fatalf("unexpected jsonpath output: %q", stdout)
+	}
+
+	_, _, err = runCommand(t,
+		"service", "--config", configFile,
+		"list",
+		"-o", "xml",
+	)
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Fatalf("expected unsupported output format error, got %v", err)
+	}
+}
@@ -19,6 +19,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +28,9 @@ var (
 	configPath        string
 	defaultConfigPath string
 	rootCmd           *cobra.Command
+	// httpTimeout and httpRetries override the HTTP client settings of every cluster, ignored if zero and negative
+	httpTimeout int
+	httpRetries int
 )
 
 func newRootCommand() *cobra.Command {
@@ -44,6 +48,9 @@ func newRootCommand() *cobra.Command {
 		Run: runFunc,
 	}
 
+	cmd.PersistentFlags().IntVar(&httpTimeout, "http-timeout", 0, "timeout in seconds of the requests to the clusters, overriding their configuration")
+	cmd.PersistentFlags().IntVar(&httpRetries, "http-retries", -1, "maximum number of retries of the failed requests to the clusters, overriding their configuration")
+
 	cmd.AddCommand(makeVersionCmd())
 	cmd.AddCommand(makeClusterCmd())
 	cmd.AddCommand(makeServiceCmd())
@@ -52,6 +59,7 @@ func newRootCommand() *cobra.Command {
 	cmd.AddCommand(makeApplyCmd())
 	cmd.AddCommand(makeInteractiveCmd())
 	cmd.AddCommand(makeDeleteCmd())
+	cmd.AddCommand(makeFDLCmd())
 
 	return cmd
 }
@@ -60,10 +68,38 @@ func runFunc(cmd *cobra.Command, args []string) {
 	cmd.Help()
 }
 
+// readConfig reads the config file setting the "--http-timeout" and "--http-retries" flags in its clusters.
+// The commands that write the config file use config.ReadConfig instead, so the flags are not stored
+func readConfig() (*config.Config, error) {
+	conf, err := config.ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range conf.Oscar {
+		if c == nil {
+			continue
+		}
+		if httpTimeout > 0 {
+			c.Timeout = httpTimeout
+		}
+		if httpRetries >= 0 {
+			retry := cluster.RetryPolicy{}
+			if c.Retry != nil {
+				retry = *c.Retry
+			}
+			retry.MaxRetries = httpRetries
+			c.Retry = &retry
+		}
+	}
+
+	return conf, nil
+}
+
 // Execute function to launch the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -86,4 +122,6 @@ func NewRootCommand() *cobra.Command {
 func resetPersistentState() {
 	configPath = defaultConfigPath
 	destinationClusterID = ""
+	httpTimeout = 0
+	httpRetries = -1
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestHTTPRetriesFlagOverridesConfig(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	content := `oscar:
+  retry-cluster:
+    endpoint: "` + server.URL + `"
+    ssl_verify: false
+    memory: 256Mi
+    log_level: INFO
+    retry:
+      max_retries: 0
+default: retry-cluster
+`
+	configFile := writeRawConfig(t, content)
+
+	if _, _, err := runCommand(t, "service", "--config", configFile, "get", "demo", "--http-retries", "2"); err == nil {
+		t.Fatalf("expected an error for the unavailable cluster")
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Fatalf("expected the flag to allow 2 retries, got %d requests", got)
+	}
+
+	atomic.StoreInt32(&requests, 0)
+	if _, _, err := runCommand(t, "service", "--config", configFile, "get", "demo"); err == nil {
+		t.Fatalf("expected an error for the unavailable cluster")
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("expected the retries of the config to be used without the flag, got %d requests", got)
+	}
+
+	stored, err := os.ReadFile(configFile)
+	if err != nil || string(stored) != content {
+		t.Fatalf("expected the config file to be left untouched, got %q, %v", stored, err)
+	}
+}
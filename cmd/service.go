@@ -46,6 +46,10 @@ func makeServiceCmd() *cobra.Command {
 	serviceCmd.AddCommand(makeServiceListFilesCmd())
 	serviceCmd.AddCommand(makeServiceRunCmd())
 	serviceCmd.AddCommand(makeServiceJobCmd())
+	serviceCmd.AddCommand(makeServiceJobsCmd())
+	serviceCmd.AddCommand(makeServiceBenchCmd())
+	serviceCmd.AddCommand(makeServiceExportCmd())
+	serviceCmd.AddCommand(makeServiceCopyCmd())
 
 	return serviceCmd
 }
@@ -0,0 +1,236 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+// batchRetryDelay is the delay before the first retry of a failed invocation, doubled on each retry
+var batchRetryDelay = 500 * time.Millisecond
+
+// batchInvoker invokes a service with the base64 encoded input, handling its response
+type batchInvoker func(input io.Reader, relativePath string) error
+
+// batchResult is the outcome of the invocation for an input file
+type batchResult struct {
+	input    string
+	attempts int
+	latency  time.Duration
+	err      error
+}
+
+func addBatchFlags(cmd *cobra.Command, withOutputDir bool) {
+	cmd.Flags().String("input-dir", "", "invoke the service once per file in the directory")
+	if withOutputDir {
+		cmd.Flags().String("output-dir", "", "directory to store the output of each file of --input-dir, keeping its name")
+	}
+	cmd.Flags().Int("parallel", 4, "maximum number of concurrent invocations with --input-dir")
+	cmd.Flags().Int("retries", 3, "number of retries of the invocations that fail due to transient errors with --input-dir")
+}
+
+// isBatchMode reports whether the service is invoked once per file of a directory
+func isBatchMode(cmd *cobra.Command) bool {
+	inputDir, _ := cmd.Flags().GetString("input-dir")
+	return inputDir != ""
+}
+
+// checkBatchFlags checks that the batch flags are not combined with the single invocation inputs
+func checkBatchFlags(cmd *cobra.Command) error {
+	inputFile, _ := cmd.Flags().GetString("file-input")
+	textInput, _ := cmd.Flags().GetString("text-input")
+	if inputFile != "" || textInput != "" {
+		return errors.New("\"--input-dir\" cannot be used together with \"--file-input\" or \"--text-input\"")
+	}
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	if parallel < 1 {
+		return errors.New("--parallel must be greater than zero")
+	}
+	retries, _ := cmd.Flags().GetInt("retries")
+	if retries < 0 {
+		return errors.New("--retries cannot be negative")
+	}
+	return nil
+}
+
+// runBatch invokes the service once per file of the input directory with at most "--parallel" concurrent
+// invocations, retrying transient failures, and prints a summary of the results
+func runBatch(cmd *cobra.Command, invoke batchInvoker) error {
+	inputDir, _ := cmd.Flags().GetString("input-dir")
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	retries, _ := cmd.Flags().GetInt("retries")
+	// Only "service run" can send the inputs untouched
+	raw, _ := cmd.Flags().GetBool("raw")
+
+	inputs, err := listBatchInputs(inputDir)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("the directory \"%s\" has no files", inputDir)
+	}
+
+	results := make([]batchResult, len(inputs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				results[index] = invokeWithRetries(cmd, invoke, inputDir, inputs[index], retries, raw)
+			}
+		}()
+	}
+	for index := range inputs {
+		queue <- index
+	}
+	close(queue)
+	wg.Wait()
+
+	return printBatchSummary(cmd, results)
+}
+
+// listBatchInputs returns the paths of the files of the directory, relative to it
+func listBatchInputs(dir string) ([]string, error) {
+	inputs := []string{}
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, relative)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the directory \"%s\": %v", dir, err)
+	}
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+func invokeWithRetries(cmd *cobra.Command, invoke batchInvoker, inputDir, relativePath string, retries int, raw bool) batchResult {
+	result := batchResult{input: relativePath}
+	delay := batchRetryDelay
+	for {
+		result.attempts++
+		start := time.Now()
+		result.err = invokeWithFile(invoke, filepath.Join(inputDir, relativePath), relativePath, raw)
+		result.latency = time.Since(start)
+
+		if result.err == nil || !isTransientError(result.err) || result.attempts > retries {
+			return result
+		}
+
+		select {
+		case <-cmd.Context().Done():
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func invokeWithFile(invoke batchInvoker, path, relativePath string, raw bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to read the file \"%s\"", path)
+	}
+	defer file.Close()
+
+	if raw {
+		return invoke(file, relativePath)
+	}
+	return invoke(base64Reader(file), relativePath)
+}
+
+// isTransientError reports whether the invocation may succeed if retried
+func isTransientError(err error) bool {
+	return errors.Is(err, cluster.ErrSendingRequest) || errors.Is(err, cluster.ErrServiceNotReady)
+}
+
+// base64Reader returns a reader with the base64 encoded content of r
+func base64Reader(r io.Reader) io.Reader {
+	reader, writer := io.Pipe()
+	encoder := base64.NewEncoder(base64.StdEncoding, writer)
+
+	// Copy the content to the encoder in a goroutine to avoid blocking the execution
+	go func() {
+		_, err := io.Copy(encoder, r)
+		encoder.Close()
+		if err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+		writer.Close()
+	}()
+
+	return reader
+}
+
+func printBatchSummary(cmd *cobra.Command, results []batchResult) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "INPUT\tRESULT\tATTEMPTS\tLATENCY")
+
+	failed := 0
+	latencies := []time.Duration{}
+	for _, r := range results {
+		outcome := "succeeded"
+		if r.err != nil {
+			outcome = fmt.Sprintf("failed: %v", r.err)
+			failed++
+		} else {
+			latencies = append(latencies, r.latency)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", r.input, outcome, r.attempts, r.latency.Round(time.Millisecond))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Fprintf(cmd.OutOrStdout(), "\n%d succeeded, %d failed", len(results)-failed, failed)
+	if len(latencies) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), ", latency p50 %s, p95 %s",
+			service.Percentile(latencies, 50).Round(time.Millisecond), service.Percentile(latencies, 95).Round(time.Millisecond))
+	}
+	fmt.Fprintln(cmd.OutOrStdout())
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d invocations failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+func serviceBenchFunc(cmd *cobra.Command, args []string) error {
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	cluster, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+	token, _ := cmd.Flags().GetString("token")
+	if endpoint != "" && token == "" {
+		return errors.New("you must specify a service token with the flag \"--token\"")
+	}
+	if token != "" && endpoint == "" {
+		return errors.New("you must specify a the cluster endpoint with the flag \"--endpoint\"")
+	}
+
+	inputFile, _ := cmd.Flags().GetString("file-input")
+	textInput, _ := cmd.Flags().GetString("text-input")
+	if inputFile != "" && textInput != "" {
+		return errors.New("you only can specify one of \"--file-input\" or \"--text-input\" flags")
+	}
+	input := []byte(textInput)
+	if inputFile != "" {
+		input, err = os.ReadFile(inputFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the file \"%s\"", inputFile)
+		}
+	}
+
+	reportPath, _ := cmd.Flags().GetString("report")
+	if reportPath != "" {
+		if ext := strings.ToLower(filepath.Ext(reportPath)); ext != ".csv" && ext != ".json" {
+			return errors.New("the report file must have a \".csv\" or \".json\" extension")
+		}
+	}
+
+	opts := service.BenchOptions{Token: token, Endpoint: endpoint, Input: input}
+	opts.Requests, _ = cmd.Flags().GetInt("requests")
+	opts.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+
+	// The results of an interrupted benchmark are reported along with the error
+	result, benchErr := service.Bench(cmd.Context(), conf.Oscar[cluster], args[0], opts)
+	if result == nil {
+		return benchErr
+	}
+
+	if reportPath != "" {
+		if err := writeBenchReport(reportPath, result.Samples); err != nil {
+			return err
+		}
+	}
+
+	err = printOutput(cmd, result, func(out io.Writer, wide bool) error {
+		return printBenchResult(out, result)
+	})
+	if err != nil {
+		return err
+	}
+	return benchErr
+}
+
+func printBenchResult(out io.Writer, result *service.BenchResult) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+	fmt.Fprintf(w, "Requests:\t%d (%d succeeded, %d failed)\n", result.Requests, result.Succeeded, result.Failed)
+	fmt.Fprintf(w, "Duration:\t%s\n", formatSeconds(result.DurationSeconds))
+	fmt.Fprintf(w, "Throughput:\t%.2f req/s\n", result.Throughput)
+	if result.Succeeded > 0 {
+		l := result.Latency
+		fmt.Fprintf(w, "Latency:\tmin %s, mean %s, p50 %s, p90 %s, p95 %s, p99 %s, max %s\n",
+			formatSeconds(l.Min), formatSeconds(l.Mean), formatSeconds(l.P50), formatSeconds(l.P90),
+			formatSeconds(l.P95), formatSeconds(l.P99), formatSeconds(l.Max))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	statuses := make([]string, 0, len(result.Errors))
+	for status := range result.Errors {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "STATUS\tERRORS")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%s\t%d\n", status, result.Errors[status])
+	}
+	return w.Flush()
+}
+
+// writeBenchReport writes every request of the benchmark as CSV or JSON depending on the extension of the file
+func writeBenchReport(path string, samples []service.BenchSample) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create the file \"%s\"", path)
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(samples)
+	}
+
+	w := csv.NewWriter(file)
+	if err := w.Write([]string{"request", "start", "latency_seconds", "status", "error"}); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		record := []string{
+			strconv.Itoa(sample.Request),
+			sample.Start.Format(time.RFC3339Nano),
+			strconv.FormatFloat(sample.LatencySeconds, 'f', -1, 64),
+			sample.Status,
+			sample.Error,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func makeServiceBenchCmd() *cobra.Command {
+	serviceBenchCmd := &cobra.Command{
+		Use:   "bench SERVICE_NAME",
+		Short: "Benchmark a synchronous service",
+		Long: `Benchmark a synchronous service (a Serverless backend in the cluster is required), sending the given number
+of requests with the given concurrency. The throughput, the latency percentiles of the successful requests
+and the errors by status code are reported, and every request can be saved in a CSV or JSON file with --report.`,
+		Args: cobra.ExactArgs(1),
+		RunE: serviceBenchFunc,
+	}
+
+	serviceBenchCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	serviceBenchCmd.Flags().StringP("endpoint", "e", "", "endpoint of a non registered cluster")
+	serviceBenchCmd.Flags().StringP("token", "t", "", "token of the service")
+	serviceBenchCmd.Flags().StringP("file-input", "f", "", "input file for the requests")
+	serviceBenchCmd.Flags().StringP("text-input", "i", "", "text input string for the requests")
+	serviceBenchCmd.Flags().IntP("requests", "n", 100, "number of requests to send")
+	serviceBenchCmd.Flags().Int("concurrency", 10, "number of concurrent requests")
+	serviceBenchCmd.Flags().String("report", "", "file to store every request, as CSV or JSON depending on its extension")
+	addOutputFlag(serviceBenchCmd, outputTable)
+
+	return serviceBenchCmd
+}
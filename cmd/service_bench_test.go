@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestServiceBenchCommand(t *testing.T) {
+	var mu sync.Mutex
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/run/echo" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if decoded, err := base64.StdEncoding.DecodeString(string(body)); err != nil || string(decoded) != "ping" {
+			t.Errorf("unexpected input %q", body)
+		}
+
+		mu.Lock()
+		count++
+		fail := count%5 == 0
+		mu.Unlock()
+		if fail {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, base64.StdEncoding.EncodeToString([]byte("pong")))
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, "bench-cluster", server.URL)
+	reportPath := filepath.Join(t.TempDir(), "report.csv")
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"bench", "echo",
+		"--text-input", "ping",
+		"--requests", "20",
+		"--concurrency", "4",
+		"--report", reportPath,
+		"-o", "json",
+	)
+	if err != nil {
+		t.Fatalf("service bench returned error: %v", err)
+	}
+
+	var result struct {
+		Requests  int            `json:"requests"`
+		Succeeded int            `json:"succeeded"`
+		Failed    int            `json:"failed"`
+		Errors    map[string]int `json:"errors"`
+		Latency   struct {
+			P50 float64 `json:"p50"`
+			P95 float64 `json:"p95"`
+		} `json:"latency_seconds"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("decoding output %q: %v", stdout, err)
+	}
+	if result.Requests != 20 || result.Succeeded != 16 || result.Failed != 4 || result.Errors["503"] != 4 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if result.Latency.P50 <= 0 || result.Latency.P95 < result.Latency.P50 {
+		t.Fatalf("unexpected latencies %+v", result.Latency)
+	}
+
+	file, err := os.Open(reportPath)
+	if err != nil {
+		t.Fatalf("opening report: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+	if len(records) != 21 || records[0][0] != "request" {
+		t.Fatalf("expected a header and a row per request, got %d rows", len(records))
+	}
+}
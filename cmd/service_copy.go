@@ -0,0 +1,208 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func serviceCopyFunc(cmd *cobra.Command, args []string) error {
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	from, _ := cmd.Flags().GetString("from")
+	if from == "" {
+		if conf.Default == "" {
+			cmd.SilenceUsage = false
+			return errors.New("source cluster not set, please provide it with --from or set a default one")
+		}
+		from = conf.Default
+	}
+	to, _ := cmd.Flags().GetString("to")
+	for _, id := range []string{from, to} {
+		if err := conf.CheckCluster(id); err != nil {
+			return err
+		}
+	}
+
+	newName, _ := cmd.Flags().GetString("name")
+	newName = strings.TrimSpace(newName)
+	if from == to && (newName == "" || newName == args[0]) {
+		return errors.New("the source and destination services are the same, please set a different cluster or name")
+	}
+
+	srcCluster := conf.Oscar[from]
+	dstCluster := conf.Oscar[to]
+
+	deployed, err := service.GetService(srcCluster, args[0])
+	if err != nil {
+		return err
+	}
+
+	// Rewrite the service for the destination cluster
+	clusterInfo, err := dstCluster.GetClusterConfig()
+	if err != nil {
+		return err
+	}
+	portable, err := service.PortableService(deployed)
+	if err != nil {
+		return err
+	}
+	if newName != "" {
+		overrideServiceName(portable, newName)
+	}
+	fdl := &service.FDL{}
+	fdl.Functions.Oscar = []map[string]*types.Service{{to: portable}}
+	svc, err := buildServiceFromFDL(fdl, to, dstCluster, clusterInfo.MinIOProvider)
+	if err != nil {
+		return err
+	}
+
+	// Copy the data before applying the service, so the copied inputs don't trigger new invocations
+	if withData, _ := cmd.Flags().GetBool("with-data"); withData {
+		for _, pair := range serviceCopyBuckets(deployed, svc) {
+			msg := fmt.Sprintf(" Copying bucket \"%s\" to \"%s\" in cluster \"%s\"", pair[0], pair[1], to)
+
+			// Make and start the spinner
+			s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+			s.Suffix = msg
+			s.Start()
+
+			files, err := copyServiceBucket(cmd.Context(), srcCluster, pair[0], dstCluster, pair[1])
+			if err != nil {
+				s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+				s.Stop()
+				return err
+			}
+			s.FinalMSG = fmt.Sprintf("%s%s (%d files)\n", successString, msg, files)
+			s.Stop()
+		}
+	}
+
+	msg := fmt.Sprintf(" Creating service \"%s\" in cluster \"%s\"", svc.Name, to)
+	method := http.MethodPost
+	exists, err := serviceExists(svc, dstCluster)
+	if err != nil {
+		return err
+	}
+	if exists {
+		msg = fmt.Sprintf(" Updating service \"%s\" in cluster \"%s\"", svc.Name, to)
+		method = http.MethodPut
+	}
+
+	// Make and start the spinner
+	s := spinner.New(spinner.CharSets[78], time.Millisecond*100)
+	s.Suffix = msg
+	s.FinalMSG = fmt.Sprintf("%s%s\n", successString, msg)
+	s.Start()
+
+	if err := service.ApplyService(svc, dstCluster, method); err != nil {
+		s.FinalMSG = fmt.Sprintf("%s%s\n", failureString, msg)
+		s.Stop()
+		return err
+	}
+	s.Stop()
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Service \"%s\" copied from cluster \"%s\" to \"%s\" as \"%s\"\n", args[0], from, to, svc.Name)
+
+	return nil
+}
+
+// serviceCopyBuckets returns the pairs of source and destination buckets used by the MinIO inputs and outputs of a copied service
+func serviceCopyBuckets(src, dst *types.Service) [][2]string {
+	pairs := [][2]string{}
+	seen := map[string]bool{}
+	add := func(srcConfigs, dstConfigs []types.StorageIOConfig) {
+		for i := range srcConfigs {
+			if i >= len(dstConfigs) || !slices.Contains(storage.DefaultStorageProvider, srcConfigs[i].Provider) {
+				continue
+			}
+			srcBucket := pathBucket(srcConfigs[i].Path)
+			dstBucket := pathBucket(dstConfigs[i].Path)
+			if srcBucket == "" || dstBucket == "" || seen[srcBucket] {
+				continue
+			}
+			seen[srcBucket] = true
+			pairs = append(pairs, [2]string{srcBucket, dstBucket})
+		}
+	}
+	add(src.Input, dst.Input)
+	add(src.Output, dst.Output)
+	return pairs
+}
+
+func pathBucket(p string) string {
+	return strings.SplitN(strings.Trim(strings.TrimSpace(p), "/"), "/", 2)[0]
+}
+
+// copyServiceBucket copies the content of a bucket to another cluster, creating the destination bucket if needed
+func copyServiceBucket(ctx context.Context, src *cluster.Cluster, srcBucket string, dst *cluster.Cluster, dstBucket string) (int, error) {
+	buckets, err := storage.ListBucketsWithContext(ctx, dst)
+	if err != nil {
+		return 0, err
+	}
+	exists := false
+	for _, bucket := range buckets {
+		if bucket.Name == dstBucket {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		// The bucket may have been created since it was listed
+		if err := storage.CreateBucket(dst, &storage.BucketInfo{Name: dstBucket}); err != nil && !errors.Is(err, cluster.ErrConflict) {
+			return 0, err
+		}
+	}
+
+	return storage.CopyBucket(ctx, src, srcBucket, dst, dstBucket)
+}
+
+func makeServiceCopyCmd() *cobra.Command {
+	serviceCopyCmd := &cobra.Command{
+		Use:     "copy SERVICE_NAME --to CLUSTER",
+		Short:   "Copy a service to another cluster",
+		Long:    "Copy the definition of a service to another cluster, optionally renaming it and copying the content of its MinIO input and output buckets.",
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"cp", "clone"},
+		RunE:    serviceCopyFunc,
+	}
+
+	serviceCopyCmd.Flags().String("from", "", "set the source cluster (default cluster if not set)")
+	serviceCopyCmd.Flags().String("to", "", "set the destination cluster")
+	serviceCopyCmd.Flags().StringP("name", "n", "", "set a new name for the service and its primary bucket")
+	serviceCopyCmd.Flags().Bool("with-data", false, "copy the content of the MinIO input and output buckets of the service")
+	serviceCopyCmd.MarkFlagRequired("to")
+
+	return serviceCopyCmd
+}
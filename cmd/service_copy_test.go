@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func TestServiceCopyCommand(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/system/services/demo" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(&types.Service{
+				Name:      "demo",
+				Image:     "img:1",
+				Script:    "echo demo",
+				Token:     "staging-token",
+				ClusterID: "staging",
+				Input:     []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+				Output:    []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/out"}},
+			})
+			return
+		}
+		t.Errorf("unexpected request to source: %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer source.Close()
+
+	var applied types.Service
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"oscar"}`)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/system/services/"):
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost && r.URL.Path == "/system/services":
+			if err := json.NewDecoder(r.Body).Decode(&applied); err != nil {
+				t.Errorf("decoding service: %v", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request to target: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer target.Close()
+
+	configFile := writeRawConfig(t, fmt.Sprintf(`oscar:
+  staging:
+    endpoint: "%s"
+    auth_user: "user"
+    auth_password: "pass"
+    ssl_verify: false
+  prod:
+    endpoint: "%s"
+    auth_user: "user"
+    auth_password: "pass"
+    ssl_verify: false
+default: staging
+`, source.URL, target.URL))
+
+	stdout, _, err := runCommand(t, "service", "copy", "demo", "--to", "prod", "--name", "demo-prod", "--config", configFile)
+	if err != nil {
+		t.Fatalf("copy returned error: %v", err)
+	}
+	if !strings.Contains(stdout, `copied from cluster "staging" to "prod" as "demo-prod"`) {
+		t.Fatalf("unexpected output: %q", stdout)
+	}
+
+	if applied.Name != "demo-prod" || applied.Token != "" || applied.ClusterID != "prod" {
+		t.Fatalf("unexpected applied service: name=%q token=%q cluster=%q", applied.Name, applied.Token, applied.ClusterID)
+	}
+	if len(applied.Input) != 1 || applied.Input[0].Path != "demo-prod/in" || applied.Output[0].Path != "demo-prod/out" {
+		t.Fatalf("expected the bucket paths to be renamed, got %+v %+v", applied.Input, applied.Output)
+	}
+	if applied.Clusters["prod"].Endpoint != target.URL {
+		t.Fatalf("expected the destination cluster to be set, got %+v", applied.Clusters)
+	}
+
+	if _, _, err := runCommand(t, "service", "copy", "demo", "--to", "staging", "--config", configFile); err == nil {
+		t.Fatalf("expected an error when copying a service onto itself")
+	}
+}
+
+func TestServiceCopyBucketsWithRename(t *testing.T) {
+	deployed := &types.Service{
+		Name:   "demo",
+		Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+		Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/out"}, {Provider: "s3.results", Path: "results/out"}},
+	}
+
+	svc, err := service.PortableService(deployed)
+	if err != nil {
+		t.Fatalf("PortableService returned error: %v", err)
+	}
+	overrideServiceName(svc, "demo-prod")
+
+	pairs := serviceCopyBuckets(deployed, svc)
+	if len(pairs) != 1 || pairs[0] != [2]string{"demo", "demo-prod"} {
+		t.Fatalf("expected the original bucket to be copied to the renamed one, got %v", pairs)
+	}
+}
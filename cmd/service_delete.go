@@ -21,14 +21,13 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/spf13/cobra"
 )
 
 func serviceRemoveFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
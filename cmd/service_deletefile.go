@@ -17,14 +17,13 @@ limitations under the License.
 package cmd
 
 import (
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
 func serviceDeleteFileFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
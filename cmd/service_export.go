@@ -0,0 +1,147 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func serviceExportFunc(cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	if all && len(args) > 0 {
+		cmd.SilenceUsage = false
+		return errors.New("cannot specify service names when using --all")
+	}
+	if !all && len(args) == 0 {
+		cmd.SilenceUsage = false
+		return errors.New("you must specify the services to export or use --all")
+	}
+
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	clusterName, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+
+	var services []*types.Service
+	if all {
+		services, err = service.ListServices(conf.Oscar[clusterName])
+		if err != nil {
+			return err
+		}
+		sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	} else {
+		for _, name := range args {
+			svc, err := service.GetService(conf.Oscar[clusterName], name)
+			if err != nil {
+				return err
+			}
+			services = append(services, svc)
+		}
+	}
+	if len(services) == 0 {
+		return fmt.Errorf("there are no services to export in cluster \"%s\"", clusterName)
+	}
+
+	fdl, scripts, err := service.ExportFDL(clusterName, services)
+	if err != nil {
+		return err
+	}
+	content, err := service.MarshalFDL(fdl)
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	force, _ := cmd.Flags().GetBool("force")
+	scriptPaths, err := writeExportedFDL(output, content, scripts, force)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "%sExported %d services from cluster \"%s\" to \"%s\"\n", successString, len(services), clusterName, output)
+	for _, scriptPath := range scriptPaths {
+		fmt.Fprintf(out, "  - %s\n", scriptPath)
+	}
+
+	return nil
+}
+
+// writeExportedFDL writes an exported FDL file along with its scripts, which are referenced relative to it, and returns the paths of the scripts
+func writeExportedFDL(output string, content []byte, scripts map[string]string, force bool) ([]string, error) {
+	dir := filepath.Dir(output)
+	scriptNames := make([]string, 0, len(scripts))
+	for name := range scripts {
+		scriptNames = append(scriptNames, name)
+	}
+	sort.Strings(scriptNames)
+
+	scriptPaths := make([]string, 0, len(scriptNames))
+	for _, name := range scriptNames {
+		scriptPaths = append(scriptPaths, filepath.Join(dir, name))
+	}
+	for _, p := range append([]string{output}, scriptPaths...) {
+		if _, err := os.Stat(p); err == nil && !force {
+			return nil, fmt.Errorf("the file \"%s\" already exists, use --force to overwrite it", p)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating directory \"%s\": %w", dir, err)
+	}
+	for i, name := range scriptNames {
+		if err := os.WriteFile(scriptPaths[i], []byte(scripts[name]), 0o755); err != nil {
+			return nil, fmt.Errorf("writing script \"%s\": %w", name, err)
+		}
+	}
+	if err := os.WriteFile(output, content, 0o644); err != nil {
+		return nil, fmt.Errorf("writing FDL file \"%s\": %w", output, err)
+	}
+
+	return scriptPaths, nil
+}
+
+func makeServiceExportCmd() *cobra.Command {
+	serviceExportCmd := &cobra.Command{
+		Use:   "export {SERVICE_NAME... | --all}",
+		Short: "Export the definition of deployed services to a FDL file",
+		Long:  "Export the definition of deployed services to a FDL file that can be applied again.\nThe scripts of the services are written as separate files next to the FDL file.",
+		Args:  cobra.ArbitraryArgs,
+		RunE:  serviceExportFunc,
+	}
+
+	serviceExportCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	serviceExportCmd.Flags().Bool("all", false, "export all the services of the cluster")
+	serviceExportCmd.Flags().StringP("output", "o", "fdl.yaml", "path of the FDL file to write")
+	serviceExportCmd.Flags().BoolP("force", "f", false, "overwrite the existing files")
+
+	return serviceExportCmd
+}
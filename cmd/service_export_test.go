@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func TestServiceExportCommand(t *testing.T) {
+	const clusterName = "export-cluster"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/system/services" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode([]*types.Service{
+				{Name: "second", Image: "img:2", Script: "echo second", Token: "token-2"},
+				{Name: "first", Image: "img:1", Script: "echo first", Token: "token-1"},
+			})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, clusterName, server.URL)
+	output := filepath.Join(t.TempDir(), "exported", "fdl.yaml")
+
+	stdout, _, err := runCommand(t, "service", "export", "--all", "-o", output, "--config", configFile)
+	if err != nil {
+		t.Fatalf("export returned error: %v", err)
+	}
+	if !strings.Contains(stdout, "Exported 2 services") {
+		t.Fatalf("unexpected output: %q", stdout)
+	}
+
+	fdl, err := service.ReadFDL(output)
+	if err != nil {
+		t.Fatalf("reading exported FDL: %v", err)
+	}
+	if len(fdl.Functions.Oscar) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(fdl.Functions.Oscar))
+	}
+	first := fdl.Functions.Oscar[0][clusterName]
+	if first == nil || first.Name != "first" || first.Script != "echo first" || first.Token != "" {
+		t.Fatalf("unexpected exported service: %+v", first)
+	}
+
+	if _, _, err := runCommand(t, "service", "export", "--all", "--output", output, "--config", configFile); err == nil {
+		t.Fatalf("expected an error when the files already exist")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(output), "second.sh")); err != nil {
+		t.Fatalf("expected script file to be written: %v", err)
+	}
+}
@@ -17,21 +17,21 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
-
-	"github.com/goccy/go-yaml"
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/spf13/cobra"
 )
 
 func serviceGetFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
 	cluster, err := getCluster(cmd, conf)
 	if err != nil {
 		return err
@@ -42,10 +42,7 @@ func serviceGetFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	yaml, _ := yaml.Marshal(svc)
-	fmt.Print(string(yaml))
-
-	return nil
+	return printOutput(cmd, svc, nil)
 }
 
 func makeServiceGetCmd() *cobra.Command {
@@ -58,6 +55,7 @@ func makeServiceGetCmd() *cobra.Command {
 	}
 
 	serviceGetCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	addOutputFlag(serviceGetCmd, outputYAML)
 
 	return serviceGetCmd
 }
@@ -22,7 +22,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/grycap/oscar-cli/pkg/storage"
 	"github.com/spf13/cobra"
@@ -48,7 +47,7 @@ func serviceGetFileFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -18,14 +18,19 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/grycap/oscar/v3/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -33,7 +38,7 @@ var DEFAULT_PROVIDER = "minio.default"
 
 func serviceJobFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -58,6 +63,36 @@ func serviceJobFunc(cmd *cobra.Command, args []string) error {
 	inputFile, _ := cmd.Flags().GetString("file-input")
 	textInput, _ := cmd.Flags().GetString("text-input")
 
+	wait, _ := cmd.Flags().GetBool("wait")
+	follow, _ := cmd.Flags().GetBool("follow")
+	downloadDir, _ := cmd.Flags().GetString("download-output")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	// Following the logs or downloading the output requires waiting for the job
+	wait = wait || follow || downloadDir != ""
+	if wait && endpoint != "" {
+		return errors.New("--wait cannot be used with the flag \"--endpoint\", the cluster must be defined in the config file")
+	}
+	if wait && interval <= 0 {
+		return errors.New("--interval must be greater than zero")
+	}
+
+	if isBatchMode(cmd) {
+		if wait {
+			return errors.New("\"--wait\", \"--follow\" and \"--download-output\" cannot be used together with \"--input-dir\"")
+		}
+		if err := checkBatchFlags(cmd); err != nil {
+			return err
+		}
+		return runBatch(cmd, func(input io.Reader, _ string) error {
+			resBody, err := service.JobService(conf.Oscar[cluster], args[0], token, endpoint, input)
+			if err != nil {
+				return err
+			}
+			return resBody.Close()
+		})
+	}
+
 	if inputFile == "" && textInput == "" {
 		return errors.New("you must specify \"--file-input\" or \"--text-input\" flag")
 	}
@@ -65,6 +100,14 @@ func serviceJobFunc(cmd *cobra.Command, args []string) error {
 		return errors.New("you only can specify one of \"--file-input\" or \"--text-input\" flags")
 	}
 
+	var waiter *jobWaiter
+	if wait {
+		waiter, err = newJobWaiter(conf.Oscar[cluster], args[0], downloadDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	var inputReader io.Reader = bytes.NewBufferString(textInput)
 
 	if inputFile != "" {
@@ -96,14 +139,122 @@ func serviceJobFunc(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	defer resBody.Close()
+	if !wait {
+		resBody.Close()
+		return nil
+	}
+
+	jobName, err := service.ReadJobResponse(resBody)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return waiter.wait(ctx, cmd, jobName, follow, interval)
+}
+
+// jobWaiter keeps the state of the cluster before invoking a service to identify the job created and its output
+type jobWaiter struct {
+	cluster     *cluster.Cluster
+	serviceName string
+	jobsBefore  map[string]*types.JobInfo
+
+	// Only set when the output has to be downloaded
+	downloadDir string
+	svc         *types.Service
+	provider    string
+	outputPath  string
+	snapshot    storage.ObjectSnapshot
+}
+
+func newJobWaiter(c *cluster.Cluster, serviceName, downloadDir string) (*jobWaiter, error) {
+	jobsBefore, err := service.ListAllLogs(c, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	waiter := &jobWaiter{
+		cluster:     c,
+		serviceName: serviceName,
+		jobsBefore:  jobsBefore,
+		downloadDir: downloadDir,
+	}
+
+	if downloadDir == "" {
+		return waiter, nil
+	}
+
+	waiter.svc, err = service.GetService(c, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	waiter.provider, err = storage.DefaultOutputProvider(waiter.svc)
+	if err != nil {
+		return nil, err
+	}
+	waiter.outputPath, err = storage.DefaultOutputPath(waiter.svc, waiter.provider)
+	if err != nil {
+		return nil, err
+	}
+	waiter.snapshot, err = storage.SnapshotObjects(c, waiter.svc, waiter.provider, waiter.outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return waiter, nil
+}
+
+// wait waits for the job to finish, identifying it if the response didn't include its name, and downloads its output
+func (w *jobWaiter) wait(ctx context.Context, cmd *cobra.Command, jobName string, follow bool, interval time.Duration) error {
+	var err error
+	if jobName == "" {
+		jobName, err = service.FindNewJob(ctx, w.cluster, w.serviceName, w.jobsBefore, interval)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Waiting for job \"%s\"...\n", jobName)
+
+	var info *types.JobInfo
+	if follow {
+		info, err = service.FollowLogs(ctx, w.cluster, w.serviceName, jobName, false, interval, cmd.OutOrStdout())
+	} else {
+		info, err = service.WaitForJob(ctx, w.cluster, w.serviceName, jobName, interval)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out waiting for job \"%s\"", jobName)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Job \"%s\" finished with status %s\n", jobName, info.Status)
+	if strings.EqualFold(info.Status, service.JobFailed) {
+		return fmt.Errorf("job \"%s\" failed", jobName)
+	}
+
+	if w.downloadDir == "" {
+		return nil
+	}
+	files, err := storage.DownloadNewObjects(cmd.Context(), w.cluster, w.svc, w.provider, w.outputPath, w.downloadDir, w.snapshot)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Fprintln(cmd.OutOrStdout(), file)
+	}
 
 	return nil
 }
 
 func makeServiceJobCmd() *cobra.Command {
 	serviceRunCmd := &cobra.Command{
-		Use:     "job SERVICE_NAME {--file-input | --text-input}",
+		Use:     "job SERVICE_NAME {--file-input | --text-input | --input-dir}",
 		Short:   "Invoke a service asynchronously (only compatible with MinIO providers)",
 		Args:    cobra.ExactArgs(1),
 		Aliases: []string{"job", "j"},
@@ -115,6 +266,12 @@ func makeServiceJobCmd() *cobra.Command {
 	serviceRunCmd.Flags().StringP("token", "t", "", "token of the service")
 	serviceRunCmd.Flags().StringP("file-input", "f", "", "input file for the request")
 	serviceRunCmd.Flags().StringP("text-input", "i", "", "text input string for the request")
+	serviceRunCmd.Flags().BoolP("wait", "w", false, "wait for the job to finish, exiting with an error if the job fails")
+	serviceRunCmd.Flags().Duration("timeout", 0, "maximum time to wait for the job, 0 means no limit")
+	serviceRunCmd.Flags().Bool("follow", false, "follow the logs of the job until it finishes (implies --wait)")
+	serviceRunCmd.Flags().String("download-output", "", "download the new files of the service's output path into the given directory once the job succeeds (implies --wait)")
+	serviceRunCmd.Flags().Duration("interval", 2*time.Second, "interval between status requests when waiting")
+	addBatchFlags(serviceRunCmd, false)
 
 	return serviceRunCmd
 }
@@ -3,10 +3,14 @@ package cmd
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/grycap/oscar/v3/pkg/types"
@@ -142,3 +146,129 @@ func TestServiceJobCommandValidation(t *testing.T) {
 		})
 	}
 }
+
+func newJobWaitServer(t *testing.T, serviceName, jobName string, statuses []string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	invoked := false
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/job/"+serviceName:
+			_, _ = io.Copy(io.Discard, r.Body)
+			invoked = true
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/"+serviceName:
+			w.Header().Set("Content-Type", "application/json")
+			if !invoked {
+				fmt.Fprint(w, `{"jobs":{"previous-job":{"status":"Succeeded"}}}`)
+				return
+			}
+			status := statuses[polls]
+			if polls < len(statuses)-1 {
+				polls++
+			}
+			fmt.Fprintf(w, `{"jobs":{"previous-job":{"status":"Succeeded"},%q:{"status":%q}}}`, jobName, status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceJobCommandWait(t *testing.T) {
+	server := newJobWaitServer(t, "batch", "batch-new", []string{"Pending", "Running", "Succeeded"})
+	configFile := writeConfigFile(t, "job-cluster", server.URL)
+
+	_, stderr, err := runCommand(t,
+		"service", "--config", configFile,
+		"job", "batch",
+		"--text-input", "data",
+		"--wait",
+		"--interval", "1ms",
+	)
+	if err != nil {
+		t.Fatalf("service job --wait returned error: %v", err)
+	}
+	if !strings.Contains(stderr, `Job "batch-new" finished with status Succeeded`) {
+		t.Fatalf("expected the job outcome in stderr, got %q", stderr)
+	}
+}
+
+func TestServiceJobCommandWaitFailedJob(t *testing.T) {
+	server := newJobWaitServer(t, "batch", "batch-new", []string{"Running", "Failed"})
+	configFile := writeConfigFile(t, "job-cluster", server.URL)
+
+	_, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"job", "batch",
+		"--text-input", "data",
+		"--wait",
+		"--interval", "1ms",
+	)
+	if err == nil || err.Error() != `job "batch-new" failed` {
+		t.Fatalf("expected the failed job error, got %v", err)
+	}
+}
+
+func TestServiceJobCommandWaitTimeout(t *testing.T) {
+	server := newJobWaitServer(t, "batch", "batch-new", []string{"Running"})
+	configFile := writeConfigFile(t, "job-cluster", server.URL)
+
+	_, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"job", "batch",
+		"--text-input", "data",
+		"--wait",
+		"--timeout", "50ms",
+		"--interval", "1ms",
+	)
+	if err == nil || err.Error() != `timed out waiting for job "batch-new"` {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestServiceJobCommandBatch(t *testing.T) {
+	var mu sync.Mutex
+	received := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/job/batch" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		decoded, _ := base64.StdEncoding.DecodeString(string(body))
+		mu.Lock()
+		received = append(received, string(decoded))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	inputDir := t.TempDir()
+	for _, name := range []string{"one", "two", "three"} {
+		if err := os.WriteFile(filepath.Join(inputDir, name+".txt"), []byte(name), 0o600); err != nil {
+			t.Fatalf("writing input: %v", err)
+		}
+	}
+	configFile := writeConfigFile(t, "job-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"job", "batch",
+		"--input-dir", inputDir,
+	)
+	if err != nil {
+		t.Fatalf("service job --input-dir returned error: %v", err)
+	}
+	if len(received) != 3 {
+		t.Fatalf("expected one job per file, got %v", received)
+	}
+	if !strings.Contains(stdout, "3 succeeded, 0 failed") {
+		t.Fatalf("expected a summary of the invocations, got %q", stdout)
+	}
+}
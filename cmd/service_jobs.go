@@ -0,0 +1,110 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// jobEntry is the representation of a job in the structured output formats
+type jobEntry struct {
+	Name string `json:"name"`
+	*types.JobInfo
+}
+
+func serviceJobsFunc(cmd *cobra.Command, args []string) {
+	cmd.Help()
+}
+
+func makeServiceJobsCmd() *cobra.Command {
+	serviceJobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Inspect the jobs of a service",
+		Args:  cobra.NoArgs,
+		Run:   serviceJobsFunc,
+	}
+
+	serviceJobsCmd.PersistentFlags().StringVar(&configPath, "config", defaultConfigPath, "set the location of the config file (YAML or JSON)")
+	serviceJobsCmd.PersistentFlags().StringP("cluster", "c", "", "set the cluster")
+
+	// Add subcommands
+	serviceJobsCmd.AddCommand(makeServiceJobsListCmd())
+	serviceJobsCmd.AddCommand(makeServiceJobsStatsCmd())
+
+	return serviceJobsCmd
+}
+
+// parseAge parses a duration like "90m", "2h" or "7d", supporting days in addition to the units of time.ParseDuration
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
+// getSinceFlag returns the oldest time allowed by the "--since" flag, zero if not set
+func getSinceFlag(cmd *cobra.Command, now time.Time) (time.Time, error) {
+	since, _ := cmd.Flags().GetString("since")
+	if strings.TrimSpace(since) == "" {
+		return time.Time{}, nil
+	}
+	age, err := parseAge(since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--since: %v", err)
+	}
+	return now.Add(-age), nil
+}
+
+// filterJobsSince returns the jobs created after the given time, a zero time matches any job
+func filterJobsSince(jobs map[string]*types.JobInfo, since time.Time) map[string]*types.JobInfo {
+	if since.IsZero() {
+		return jobs
+	}
+	filtered := map[string]*types.JobInfo{}
+	for name, info := range jobs {
+		if !service.JobTimestamp(info).Before(since) {
+			filtered[name] = info
+		}
+	}
+	return filtered
+}
+
+// newestJobNames returns the job names sorted from the most recent to the oldest
+func newestJobNames(jobs map[string]*types.JobInfo) []string {
+	names := sortedJobNames(jobs)
+	sort.SliceStable(names, func(i, j int) bool {
+		return service.JobTimestamp(jobs[names[i]]).After(service.JobTimestamp(jobs[names[j]]))
+	})
+	return names
+}
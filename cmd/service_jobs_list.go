@@ -0,0 +1,100 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+func serviceJobsListFunc(cmd *cobra.Command, args []string) error {
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	statusSlice, _ := cmd.Flags().GetStringSlice("status")
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 0 {
+		return errors.New("--limit cannot be negative")
+	}
+	since, err := getSinceFlag(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+
+	cluster, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+
+	allJobs, err := service.ListAllLogs(conf.Oscar[cluster], args[0])
+	if err != nil {
+		return err
+	}
+	jobs := filterJobsSince(filterLogs(allJobs, statusSlice), since)
+
+	names := newestJobNames(jobs)
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+	entries := make([]jobEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, jobEntry{Name: name, JobInfo: jobs[name]})
+	}
+
+	return printOutput(cmd, entries, func(out io.Writer, wide bool) error {
+		if len(entries) == 0 {
+			fmt.Fprintln(out, "No jobs found")
+			return nil
+		}
+		w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, jobListHeader(wide))
+		for _, entry := range entries {
+			fmt.Fprintln(w, formatJobRow(entry.Name, entry.JobInfo, wide))
+		}
+		return w.Flush()
+	})
+}
+
+func makeServiceJobsListCmd() *cobra.Command {
+	serviceJobsListCmd := &cobra.Command{
+		Use:     "list SERVICE_NAME",
+		Short:   "List the jobs of a service, from the most recent to the oldest",
+		Args:    cobra.ExactArgs(1),
+		Aliases: []string{"ls"},
+		RunE:    serviceJobsListFunc,
+	}
+
+	serviceJobsListCmd.Flags().StringSliceP("status", "s", []string{}, "filter by status (Pending, Running, Succeeded or Failed), multiple values can be specified by a comma-separated string")
+	serviceJobsListCmd.Flags().String("since", "", "only list the jobs created within the given duration, e.g. 30m, 2h or 7d")
+	serviceJobsListCmd.Flags().IntP("limit", "n", 0, "maximum number of jobs to list, 0 means no limit")
+	addOutputFlag(serviceJobsListCmd, outputTable)
+
+	return serviceJobsListCmd
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/spf13/cobra"
+)
+
+func serviceJobsStatsFunc(cmd *cobra.Command, args []string) error {
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	period, _ := cmd.Flags().GetString("period")
+	periodDuration, err := parseAge(period)
+	if err != nil {
+		return fmt.Errorf("--period: %v", err)
+	}
+	if periodDuration <= 0 {
+		return errors.New("--period must be greater than zero")
+	}
+	since, err := getSinceFlag(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+
+	cluster, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+
+	allJobs, err := service.ListAllLogs(conf.Oscar[cluster], args[0])
+	if err != nil {
+		return err
+	}
+	stats := service.ComputeJobStats(filterJobsSince(allJobs, since), periodDuration)
+
+	return printOutput(cmd, stats, func(out io.Writer, wide bool) error {
+		return printJobStats(out, stats)
+	})
+}
+
+func printJobStats(out io.Writer, stats *service.JobStats) error {
+	if stats.Total == 0 {
+		fmt.Fprintln(out, "No jobs found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+	fmt.Fprintf(w, "Jobs:\t%d\n", stats.Total)
+	statuses := make([]string, 0, len(stats.Statuses))
+	for status := range stats.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "  %s:\t%d\n", status, stats.Statuses[status])
+	}
+	fmt.Fprintf(w, "Failure rate:\t%s\n", formatRate(stats.FailureRate))
+	fmt.Fprintf(w, "Duration p50:\t%s\n", formatSeconds(stats.DurationP50Seconds))
+	fmt.Fprintf(w, "Duration p95:\t%s\n", formatSeconds(stats.DurationP95Seconds))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(stats.Periods) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "PERIOD START\tJOBS\tFAILED\tFAILURE RATE")
+	for _, p := range stats.Periods {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", p.Start.Format(timeFormat), p.Jobs, p.Failed, formatRate(p.FailureRate))
+	}
+	return w.Flush()
+}
+
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+func makeServiceJobsStatsCmd() *cobra.Command {
+	serviceJobsStatsCmd := &cobra.Command{
+		Use:   "stats SERVICE_NAME",
+		Short: "Show statistics about the jobs of a service",
+		Long: `Show statistics about the jobs of a service: the number of jobs per status, the failure rate
+(failed jobs over the finished ones), the median (p50) and 95th percentile (p95) of the job durations,
+and the failure rate over time grouped by the given period.`,
+		Args: cobra.ExactArgs(1),
+		RunE: serviceJobsStatsFunc,
+	}
+
+	serviceJobsStatsCmd.Flags().String("since", "", "only take into account the jobs created within the given duration, e.g. 30m, 2h or 7d")
+	serviceJobsStatsCmd.Flags().String("period", "1d", "length of the periods used to report the failure rate over time, e.g. 1h or 1d")
+	addOutputFlag(serviceJobsStatsCmd, outputTable)
+
+	return serviceJobsStatsCmd
+}
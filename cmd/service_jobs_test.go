@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newJobsServer(t *testing.T, serviceName string) *httptest.Server {
+	t.Helper()
+
+	now := time.Now().UTC()
+	job := func(status string, age, duration time.Duration) string {
+		created := now.Add(-age)
+		return fmt.Sprintf(`{"status":%q,"creation_time":%q,"start_time":%q,"finish_time":%q}`,
+			status, created.Format(time.RFC3339), created.Format(time.RFC3339), created.Add(duration).Format(time.RFC3339))
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/system/logs/"+serviceName {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"jobs":{"job-old":%s,"job-failed":%s},"next_page":"2"}`,
+				job("Succeeded", 72*time.Hour, 10*time.Second), job("Failed", 3*time.Hour, 20*time.Second))
+			return
+		}
+		fmt.Fprintf(w, `{"jobs":{"job-recent":%s,"job-latest":%s}}`,
+			job("Succeeded", 1*time.Hour, 30*time.Second), job("Succeeded", 10*time.Minute, 40*time.Second))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceJobsListFilters(t *testing.T) {
+	server := newJobsServer(t, "demo")
+	configFile := writeConfigFile(t, "jobs-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"jobs", "list", "demo",
+		"--status", "succeeded",
+		"--since", "2d",
+		"--limit", "1",
+		"-o", "json",
+	)
+	if err != nil {
+		t.Fatalf("service jobs list returned error: %v", err)
+	}
+
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("decoding output %q: %v", stdout, err)
+	}
+	if len(entries) != 1 || entries[0]["name"] != "job-latest" || entries[0]["status"] != "Succeeded" {
+		t.Fatalf("expected only the latest succeeded job, got %v", entries)
+	}
+}
+
+func TestServiceJobsStats(t *testing.T) {
+	server := newJobsServer(t, "demo")
+	configFile := writeConfigFile(t, "jobs-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"jobs", "stats", "demo",
+		"-o", "json",
+	)
+	if err != nil {
+		t.Fatalf("service jobs stats returned error: %v", err)
+	}
+
+	var stats struct {
+		Total       int            `json:"total"`
+		Statuses    map[string]int `json:"statuses"`
+		FailureRate float64        `json:"failure_rate"`
+		P50         float64        `json:"duration_p50_seconds"`
+		P95         float64        `json:"duration_p95_seconds"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("decoding output %q: %v", stdout, err)
+	}
+	if stats.Total != 4 || stats.Statuses["Succeeded"] != 3 || stats.Statuses["Failed"] != 1 {
+		t.Fatalf("expected the jobs of all the pages to be counted, got %+v", stats)
+	}
+	if stats.FailureRate != 0.25 || stats.P50 != 20 || stats.P95 != 40 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90m": 90 * time.Minute,
+		"2h":  2 * time.Hour,
+		"7d":  7 * 24 * time.Hour,
+	}
+	for value, expected := range cases {
+		got, err := parseAge(value)
+		if err != nil || got != expected {
+			t.Fatalf("parseAge(%q) = %v, %v; expected %v", value, got, err, expected)
+		}
+	}
+	for _, value := range []string{"", "abc", "-1h", "xd"} {
+		if _, err := parseAge(value); err == nil {
+			t.Fatalf("expected an error for %q", value)
+		}
+	}
+}
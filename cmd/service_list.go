@@ -18,21 +18,36 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 func serviceListFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
+	if _, err := getOutputFormat(cmd); err != nil {
+		return err
+	}
+
+	clusterIDs, err := getClusters(cmd, conf)
+	if err != nil {
+		return err
+	}
+	if clusterIDs != nil {
+		return serviceListClusters(cmd, conf, clusterIDs)
+	}
+
 	cluster, err := getCluster(cmd, conf)
 	if err != nil {
 		return err
@@ -43,34 +58,91 @@ func serviceListFunc(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(svcList) == 0 {
-		fmt.Println("There are no services in the cluster")
-	} else {
+	return printOutput(cmd, svcList, func(out io.Writer, wide bool) error {
+		if len(svcList) == 0 {
+			fmt.Fprintln(out, "There are no services in the cluster")
+			return nil
+		}
 		// Prepare tabwriter
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
 		// Print header
-		fmt.Fprintln(w, "NAME\tIMAGE\tCPU\tMEMORY")
+		fmt.Fprintln(w, serviceListHeader(wide))
 		// Print services
 		for _, s := range svcList {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.Image, s.CPU, s.Memory)
+			fmt.Fprintln(w, serviceListRow(s, wide))
+		}
+		return w.Flush()
+	})
+}
+
+// serviceListClusters prints the services of several clusters, reporting the clusters that failed
+func serviceListClusters(cmd *cobra.Command, conf *config.Config, clusterIDs []string) error {
+	results := forEachCluster(conf, clusterIDs, func(c *cluster.Cluster) (interface{}, error) {
+		return service.ListServices(c)
+	})
+
+	err := printOutput(cmd, clusterOutputs(results), func(out io.Writer, wide bool) error {
+		// Prepare tabwriter
+		w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+		// Print header
+		fmt.Fprintf(w, "CLUSTER\t%s\n", serviceListHeader(wide))
+		// Print services
+		for _, r := range results {
+			if r.err != nil {
+				continue
+			}
+			for _, s := range r.value.([]*types.Service) {
+				fmt.Fprintf(w, "%s\t%s\n", r.cluster, serviceListRow(s, wide))
+			}
 		}
-		w.Flush()
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return reportClusterErrors(cmd, results)
+}
+
+func serviceListHeader(wide bool) string {
+	if wide {
+		return "NAME\tIMAGE\tCPU\tMEMORY\tINPUTS\tOUTPUTS\tOWNER"
 	}
+	return "NAME\tIMAGE\tCPU\tMEMORY"
+}
 
-	return nil
+func serviceListRow(s *types.Service, wide bool) string {
+	row := fmt.Sprintf("%s\t%s\t%s\t%s", s.Name, s.Image, s.CPU, s.Memory)
+	if wide {
+		row = fmt.Sprintf("%s\t%s\t%s\t%s", row, formatStorageIO(s.Input), formatStorageIO(s.Output), s.Owner)
+	}
+	return row
+}
+
+// formatStorageIO joins the storage inputs or outputs of a service in the form "provider:path"
+func formatStorageIO(configs []types.StorageIOConfig) string {
+	if len(configs) == 0 {
+		return "-"
+	}
+	items := make([]string, 0, len(configs))
+	for _, c := range configs {
+		items = append(items, fmt.Sprintf("%s:%s", c.Provider, c.Path))
+	}
+	return strings.Join(items, ",")
 }
 
 func makeServiceListCmd() *cobra.Command {
 	serviceListCmd := &cobra.Command{
 		Use:     "list",
-		Short:   "List the available services in a cluster",
+		Short:   "List the available services in one or more clusters",
 		Args:    cobra.NoArgs,
 		Aliases: []string{"ls"},
 		RunE:    serviceListFunc,
 	}
 
 	serviceListCmd.Flags().StringP("cluster", "c", "", "set the cluster")
+	addMultiClusterFlags(serviceListCmd)
+	addOutputFlag(serviceListCmd, outputTable)
 
 	return serviceListCmd
 }
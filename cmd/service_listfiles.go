@@ -19,14 +19,13 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/storage"
 	"github.com/spf13/cobra"
 )
 
 func serviceListFilesFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -40,6 +40,7 @@ func makeServiceLogsCmd() *cobra.Command {
 	serviceLogsCmd.AddCommand(makeServiceLogsGetCmd())
 	serviceLogsCmd.AddCommand(makeServiceLogsListCmd())
 	serviceLogsCmd.AddCommand(makeServiceLogsRemoveCmd())
+	serviceLogsCmd.AddCommand(makeServiceLogsExportCmd())
 
 	return serviceLogsCmd
 }
@@ -0,0 +1,283 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+const logsExportIndexFile = "index.json"
+
+// logsExportIndex is the index stored along with the exported logs
+type logsExportIndex struct {
+	Service string                      `json:"service"`
+	Cluster string                      `json:"cluster"`
+	Jobs    map[string]*logsExportEntry `json:"jobs"`
+}
+
+type logsExportEntry struct {
+	*types.JobInfo
+	LogFile    string    `json:"log_file"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// logsExporter downloads the logs of the jobs of a service keeping the index up to date,
+// so an interrupted export can be resumed
+type logsExporter struct {
+	cluster     *cluster.Cluster
+	serviceName string
+	dir         string
+	timestamps  bool
+
+	mu    sync.Mutex
+	index *logsExportIndex
+}
+
+func serviceLogsExportFunc(cmd *cobra.Command, args []string) error {
+	// Read the config file
+	conf, err := readConfig()
+	if err != nil {
+		return err
+	}
+
+	clusterName, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
+	}
+
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		// "--out" is kept as an alias of "--dir"
+		dir, _ = cmd.Flags().GetString("out")
+	}
+	if dir == "" {
+		cmd.SilenceUsage = false
+		return errors.New("the directory to store the logs must be set with \"--dir\"")
+	}
+	statusSlice, _ := cmd.Flags().GetStringSlice("status")
+	timestamps, _ := cmd.Flags().GetBool("show-timestamps")
+	workers, _ := cmd.Flags().GetInt("parallel")
+	if workers < 1 {
+		return errors.New("--parallel must be greater than zero")
+	}
+	since, err := getSinceFlag(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create the directory \"%s\"", dir)
+	}
+	exporter := &logsExporter{
+		cluster:     conf.Oscar[clusterName],
+		serviceName: args[0],
+		dir:         dir,
+		timestamps:  timestamps,
+	}
+	if err := exporter.readIndex(clusterName); err != nil {
+		return err
+	}
+
+	allJobs, err := service.ListAllLogs(exporter.cluster, exporter.serviceName)
+	if err != nil {
+		return err
+	}
+	jobs := filterJobsSince(filterLogs(allJobs, statusSlice), since)
+
+	pending := []string{}
+	for _, jobName := range sortedJobNames(jobs) {
+		if filepath.Base(jobName) != jobName || jobName == "." || jobName == ".." {
+			return fmt.Errorf("the job name \"%s\" is not valid", jobName)
+		}
+		if !exporter.isExported(jobName) {
+			pending = append(pending, jobName)
+		}
+	}
+	skipped := len(jobs) - len(pending)
+
+	errs := exporter.export(cmd, jobs, pending, workers)
+	for _, jobName := range pending {
+		if err, failed := errs[jobName]; failed {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%sjob \"%s\": %v\n", failureString, jobName, err)
+		}
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%sExported the logs of %d jobs from service \"%s\" into \"%s\"", successString, len(pending)-len(errs), exporter.serviceName, dir)
+	if skipped > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), " (%d already exported)", skipped)
+	}
+	fmt.Fprintln(cmd.OutOrStdout())
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d jobs could not be exported, run the command again to resume the export", len(errs), len(pending))
+	}
+	return nil
+}
+
+// readIndex loads the index of a previous export in the directory, if any
+func (e *logsExporter) readIndex(clusterName string) error {
+	e.index = &logsExportIndex{
+		Service: e.serviceName,
+		Cluster: clusterName,
+		Jobs:    map[string]*logsExportEntry{},
+	}
+
+	content, err := os.ReadFile(filepath.Join(e.dir, logsExportIndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	previous := &logsExportIndex{}
+	if err := json.Unmarshal(content, previous); err != nil {
+		return fmt.Errorf("the index of the directory \"%s\" is not valid: %v", e.dir, err)
+	}
+	if previous.Service != e.serviceName || previous.Cluster != clusterName {
+		return fmt.Errorf("the directory \"%s\" contains the logs of service \"%s\" from cluster \"%s\"", e.dir, previous.Service, previous.Cluster)
+	}
+	if previous.Jobs != nil {
+		e.index.Jobs = previous.Jobs
+	}
+	return nil
+}
+
+// isExported reports whether the logs of the job were exported once it had finished, so they can't change
+func (e *logsExporter) isExported(jobName string) bool {
+	entry, ok := e.index.Jobs[jobName]
+	if !ok || !service.IsJobFinished(entry.JobInfo) {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(e.dir, entry.LogFile))
+	return err == nil
+}
+
+// export downloads the logs of the pending jobs with at most workers concurrent requests, returning the errors by job name
+func (e *logsExporter) export(cmd *cobra.Command, jobs map[string]*types.JobInfo, pending []string, workers int) map[string]error {
+	var mu sync.Mutex
+	errs := map[string]error{}
+	queue := make(chan string)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for jobName := range queue {
+				err := cmd.Context().Err()
+				if err == nil {
+					err = e.exportJob(jobName, jobs[jobName])
+				}
+				if err != nil {
+					mu.Lock()
+					errs[jobName] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, jobName := range pending {
+		queue <- jobName
+	}
+	close(queue)
+	wg.Wait()
+
+	return errs
+}
+
+// exportJob writes the logs of a job and records it in the index. The log file is renamed into place once
+// complete, so an interrupted export never leaves truncated logs behind
+func (e *logsExporter) exportJob(jobName string, info *types.JobInfo) error {
+	logs, err := service.GetLogs(e.cluster, e.serviceName, jobName, e.timestamps)
+	if err != nil {
+		return err
+	}
+
+	logFile := jobName + ".log"
+	if err := writeFileAtomic(filepath.Join(e.dir, logFile), []byte(logs)); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.index.Jobs[jobName] = &logsExportEntry{
+		JobInfo:    info,
+		LogFile:    logFile,
+		ExportedAt: time.Now().UTC(),
+	}
+	content, err := json.MarshalIndent(e.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(e.dir, logsExportIndexFile), content)
+}
+
+// writeFileAtomic writes the file through a temporary file in the same directory that is renamed once written
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to write the file \"%s\"", path)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write the file \"%s\"", path)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write the file \"%s\"", path)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func makeServiceLogsExportCmd() *cobra.Command {
+	serviceLogsExportCmd := &cobra.Command{
+		Use:   "export SERVICE_NAME --dir DIRECTORY",
+		Short: "Export the logs of the jobs of a service into a directory",
+		Long: `Export the logs of the jobs of a service into a directory, one "<JOB_NAME>.log" file per job, along with
+an "index.json" file with the metadata of the jobs. Running the command again on the same directory resumes
+an interrupted export, skipping the jobs that had already finished when their logs were exported.`,
+		Args: cobra.ExactArgs(1),
+		RunE: serviceLogsExportFunc,
+	}
+
+	serviceLogsExportCmd.Flags().String("dir", "", "directory to store the logs")
+	serviceLogsExportCmd.Flags().String("out", "", "directory to store the logs (alias of --dir)")
+	serviceLogsExportCmd.Flags().MarkHidden("out")
+	serviceLogsExportCmd.Flags().StringSliceP("status", "s", []string{}, "filter by status (Pending, Running, Succeeded or Failed), multiple values can be specified by a comma-separated string")
+	serviceLogsExportCmd.Flags().String("since", "", "only export the logs of the jobs created within the given duration, e.g. 30m, 2h or 7d")
+	serviceLogsExportCmd.Flags().BoolP("show-timestamps", "t", false, "show timestamps in the logs")
+	serviceLogsExportCmd.Flags().Int("parallel", 8, "maximum number of concurrent downloads")
+
+	return serviceLogsExportCmd
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestServiceLogsExportResumes(t *testing.T) {
+	var mu sync.Mutex
+	fetched := map[string]int{}
+	failJob2 := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/system/logs/demo":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jobs":{"job-1":{"status":"Succeeded"},"job-2":{"status":"Failed"},"job-3":{"status":"Running"}}}`))
+		case "/system/logs/demo/job-1", "/system/logs/demo/job-2", "/system/logs/demo/job-3":
+			jobName := filepath.Base(r.URL.Path)
+			fetched[jobName]++
+			if jobName == "job-2" && failJob2 {
+				http.Error(w, "unavailable", http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write([]byte("logs of " + jobName))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	configFile := writeConfigFile(t, "export-cluster", server.URL)
+	dir := filepath.Join(t.TempDir(), "out")
+	args := []string{"service", "--config", configFile, "logs", "export", "demo", "--parallel", "2"}
+
+	if _, _, err := runCommand(t, append(args, "--dir", dir)...); err == nil || !strings.Contains(err.Error(), "1 of 3 jobs could not be exported") {
+		t.Fatalf("expected the export of job-2 to fail, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "job-2.log")); !os.IsNotExist(err) {
+		t.Fatalf("expected no log file for the failed job, got %v", err)
+	}
+
+	mu.Lock()
+	failJob2 = false
+	mu.Unlock()
+
+	// "--out" is still accepted as an alias of "--dir"
+	stdout, _, err := runCommand(t, append(args, "--out", dir)...)
+	if err != nil {
+		t.Fatalf("resuming the export returned error: %v", err)
+	}
+	if !strings.Contains(stdout, "(1 already exported)") {
+		t.Fatalf("expected the finished job to be skipped, got %q", stdout)
+	}
+	// job-1 finished before the first export, job-3 was running so its logs are exported again
+	if fetched["job-1"] != 1 || fetched["job-2"] != 2 || fetched["job-3"] != 2 {
+		t.Fatalf("unexpected log requests %v", fetched)
+	}
+
+	for _, jobName := range []string{"job-1", "job-2", "job-3"} {
+		content, err := os.ReadFile(filepath.Join(dir, jobName+".log"))
+		if err != nil || string(content) != "logs of "+jobName {
+			t.Fatalf("unexpected logs for %s: %q, %v", jobName, content, err)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, logsExportIndexFile))
+	if err != nil {
+		t.Fatalf("reading index: %v", err)
+	}
+	var index logsExportIndex
+	if err := json.Unmarshal(content, &index); err != nil {
+		t.Fatalf("decoding index: %v", err)
+	}
+	if index.Service != "demo" || len(index.Jobs) != 3 || index.Jobs["job-2"].Status != "Failed" || index.Jobs["job-2"].LogFile != "job-2.log" {
+		t.Fatalf("unexpected index %+v", index)
+	}
+}
@@ -19,8 +19,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/spf13/cobra"
 )
@@ -45,7 +46,7 @@ func serviceLogsGetFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -71,6 +72,26 @@ func serviceLogsGetFunc(cmd *cobra.Command, args []string) error {
 		jobName = args[1]
 	}
 
+	follow, _ := cmd.Flags().GetBool("follow")
+	if follow {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval <= 0 {
+			return fmt.Errorf("--interval must be greater than zero")
+		}
+
+		info, err := service.FollowLogs(cmd.Context(), conf.Oscar[cluster], serviceName, jobName, showTimestamps, interval, cmd.OutOrStdout())
+		if errors.Is(err, service.ErrJobNotFound) {
+			return fmt.Errorf("job %q not found in service %q", jobName, serviceName)
+		}
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(info.Status, service.JobFailed) {
+			return fmt.Errorf("job %q failed", jobName)
+		}
+		return nil
+	}
+
 	logs, err := service.GetLogs(conf.Oscar[cluster], serviceName, jobName, showTimestamps)
 	if err != nil {
 		return err
@@ -92,6 +113,8 @@ func makeServiceLogsGetCmd() *cobra.Command {
 
 	serviceLogsGetCmd.Flags().BoolP("latest", "l", false, "get logs from the most recent job")
 	serviceLogsGetCmd.Flags().BoolP("show-timestamps", "t", false, "show timestamps in the logs")
+	serviceLogsGetCmd.Flags().BoolP("follow", "f", false, "follow the logs until the job finishes, exiting with an error if the job fails")
+	serviceLogsGetCmd.Flags().Duration("interval", 2*time.Second, "interval between log requests when following")
 
 	return serviceLogsGetCmd
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newFollowLogsServer(t *testing.T, serviceName, jobName string, statuses, logs []string) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+	step := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/"+serviceName:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jobs":{%q:{"status":%q}}}`, jobName, statuses[step])
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/"+serviceName+"/"+jobName:
+			fmt.Fprint(w, logs[step])
+			if step < len(logs)-1 {
+				step++
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestServiceLogsGetFollow(t *testing.T) {
+	server := newFollowLogsServer(t, "demo", "job-1",
+		[]string{"Running", "Running", "Succeeded"},
+		[]string{"first\nsec", "first\nsecond\n", "first\nsecond\nlast"},
+	)
+	configFile := writeConfigFile(t, "follow-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "logs", "get", "demo", "job-1",
+		"--config", configFile,
+		"--follow",
+		"--interval", "1ms",
+	)
+	if err != nil {
+		t.Fatalf("service logs get --follow returned error: %v", err)
+	}
+	if stdout != "first\nsecond\nlast\n" {
+		t.Fatalf("expected each line to be printed once, got %q", stdout)
+	}
+}
+
+func TestServiceLogsGetFollowFailedJob(t *testing.T) {
+	server := newFollowLogsServer(t, "demo", "job-1",
+		[]string{"Running", "Failed"},
+		[]string{"starting\n", "starting\nboom\n"},
+	)
+	configFile := writeConfigFile(t, "follow-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "logs", "get", "demo", "job-1",
+		"--config", configFile,
+		"--follow",
+		"--interval", "1ms",
+	)
+	if err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("expected an error for the failed job, got %v", err)
+	}
+	if stdout != "starting\nboom\n" {
+		t.Fatalf("unexpected logs %q", stdout)
+	}
+}
@@ -18,10 +18,12 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/grycap/oscar/v3/pkg/types"
@@ -32,85 +34,144 @@ const timeFormat = "2006-01-02 15:04:05"
 
 func serviceLogsListFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
 
-	cluster, err := getCluster(cmd, conf)
-	if err != nil {
+	if _, err := getOutputFormat(cmd); err != nil {
 		return err
 	}
 
 	statusSlice, _ := cmd.Flags().GetStringSlice("status")
-	var allLogs map[string]*types.JobInfo
-	logMap, err := service.ListLogs(conf.Oscar[cluster], args[0], "")
+
+	clusterIDs, err := getClusters(cmd, conf)
 	if err != nil {
 		return err
 	}
-	allLogs = logMap.Jobs
-
-	for logMap.NextPage != "" {
-		logMap, err = service.ListLogs(conf.Oscar[cluster], args[0], logMap.NextPage)
+	if clusterIDs != nil {
+		return serviceLogsListClusters(cmd, conf, clusterIDs, args[0], statusSlice)
+	}
 
-		// Agregar todos los logs de la siguiente página a allLogs
-		for k, v := range logMap.Jobs {
-			allLogs[k] = v
-		}
-		if err != nil {
-			return err
-		}
+	cluster, err := getCluster(cmd, conf)
+	if err != nil {
+		return err
 	}
 
-	printLogMap(allLogs, statusSlice)
+	allLogs, err := service.ListAllLogs(conf.Oscar[cluster], args[0])
+	if err != nil {
+		return err
+	}
+	logs := filterLogs(allLogs, statusSlice)
 
-	return nil
+	return printOutput(cmd, logs, func(out io.Writer, wide bool) error {
+		if len(allLogs) == 0 {
+			fmt.Fprintln(out, "This service has no logs")
+			return nil
+		}
+		// Prepare tabwriter
+		w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
+		// Print header
+		fmt.Fprintln(w, jobListHeader(wide))
+		// Print job's logs
+		for _, jobName := range sortedJobNames(logs) {
+			fmt.Fprintln(w, formatJobRow(jobName, logs[jobName], wide))
+		}
+		return w.Flush()
+	})
 }
 
-func printLogMap(logMap map[string]*types.JobInfo, statusSlice []string) {
-	if len(logMap) == 0 {
-		fmt.Println("This service has no logs")
-	} else {
+// serviceLogsListClusters prints the logs of a service in several clusters, reporting the clusters that failed
+func serviceLogsListClusters(cmd *cobra.Command, conf *config.Config, clusterIDs []string, name string, statusSlice []string) error {
+	results := forEachCluster(conf, clusterIDs, func(c *cluster.Cluster) (interface{}, error) {
+		allLogs, err := service.ListAllLogs(c, name)
+		if err != nil {
+			return nil, err
+		}
+		return filterLogs(allLogs, statusSlice), nil
+	})
+
+	err := printOutput(cmd, clusterOutputs(results), func(out io.Writer, wide bool) error {
 		// Prepare tabwriter
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		w := tabwriter.NewWriter(out, 0, 8, 2, '\t', 0)
 		// Print header
-		fmt.Fprintln(w, "JOB NAME\tSTATUS\tCREATION TIME\tSTART TIME\tFINISH TIME")
-
-		for jobName, jobInfo := range logMap {
-			// Filter by status
-			if len(statusSlice) > 0 {
-				found := false
-				for _, status := range statusSlice {
-					if strings.EqualFold(status, jobInfo.Status) {
-						found = true
-						break
-					}
-				}
-				if !found {
-					continue
-				}
-			}
-
-			// Prepare times
-			creationTime := ""
-			if jobInfo.CreationTime != nil {
-				creationTime = jobInfo.CreationTime.Format(timeFormat)
+		fmt.Fprintf(w, "CLUSTER\t%s\n", jobListHeader(wide))
+		for _, r := range results {
+			if r.err != nil {
+				continue
 			}
-			startTime := ""
-			if jobInfo.StartTime != nil {
-				startTime = jobInfo.StartTime.Format(timeFormat)
+			logs := r.value.(map[string]*types.JobInfo)
+			for _, jobName := range sortedJobNames(logs) {
+				fmt.Fprintf(w, "%s\t%s\n", r.cluster, formatJobRow(jobName, logs[jobName], wide))
 			}
-			finishTime := ""
-			if jobInfo.FinishTime != nil {
-				finishTime = jobInfo.FinishTime.Format(timeFormat)
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return reportClusterErrors(cmd, results)
+}
+
+// filterLogs returns the jobs whose status is one of the provided ones, an empty list matches any status
+func filterLogs(logMap map[string]*types.JobInfo, statusSlice []string) map[string]*types.JobInfo {
+	filtered := map[string]*types.JobInfo{}
+	for jobName, jobInfo := range logMap {
+		if len(statusSlice) == 0 {
+			filtered[jobName] = jobInfo
+			continue
+		}
+		for _, status := range statusSlice {
+			if strings.EqualFold(status, jobInfo.Status) {
+				filtered[jobName] = jobInfo
+				break
 			}
+		}
+	}
+	return filtered
+}
+
+func sortedJobNames(logMap map[string]*types.JobInfo) []string {
+	names := make([]string, 0, len(logMap))
+	for jobName := range logMap {
+		names = append(names, jobName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func jobListHeader(wide bool) string {
+	if wide {
+		return "JOB NAME\tSTATUS\tCREATION TIME\tSTART TIME\tFINISH TIME\tDURATION"
+	}
+	return "JOB NAME\tSTATUS\tCREATION TIME\tSTART TIME\tFINISH TIME"
+}
+
+func formatJobRow(jobName string, jobInfo *types.JobInfo, wide bool) string {
+	// Prepare times
+	creationTime := ""
+	if jobInfo.CreationTime != nil {
+		creationTime = jobInfo.CreationTime.Format(timeFormat)
+	}
+	startTime := ""
+	if jobInfo.StartTime != nil {
+		startTime = jobInfo.StartTime.Format(timeFormat)
+	}
+	finishTime := ""
+	if jobInfo.FinishTime != nil {
+		finishTime = jobInfo.FinishTime.Format(timeFormat)
+	}
 
-			// Print job's logs
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", jobName, jobInfo.Status, creationTime, startTime, finishTime)
+	row := fmt.Sprintf("%s\t%s\t%s\t%s\t%s", jobName, jobInfo.Status, creationTime, startTime, finishTime)
+	if wide {
+		duration := ""
+		if jobInfo.StartTime != nil && jobInfo.FinishTime != nil {
+			duration = jobInfo.FinishTime.Sub(jobInfo.StartTime.Time).String()
 		}
-		w.Flush()
+		row = fmt.Sprintf("%s\t%s", row, duration)
 	}
+	return row
 }
 
 func makeServiceLogsListCmd() *cobra.Command {
@@ -123,6 +184,8 @@ func makeServiceLogsListCmd() *cobra.Command {
 	}
 
 	serviceLogsListCmd.Flags().StringSliceP("status", "s", []string{}, "filter by status (Pending, Running, Succeeded or Failed), multiple values can be specified by a comma-separated string")
+	addMultiClusterFlags(serviceLogsListCmd)
+	addOutputFlag(serviceLogsListCmd, outputTable)
 
 	return serviceLogsListCmd
 }
@@ -19,15 +19,16 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"time"
 
-	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/spf13/cobra"
 )
 
 func serviceLogsRemoveFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -40,6 +41,10 @@ func serviceLogsRemoveFunc(cmd *cobra.Command, args []string) error {
 	all, _ := cmd.Flags().GetBool("all")
 	succeeded, _ := cmd.Flags().GetBool("succeeded")
 
+	if isLogsPruneMode(cmd) {
+		return pruneServiceLogs(cmd, conf.Oscar[cluster], args[0])
+	}
+
 	if succeeded {
 		err := service.RemoveLogs(conf.Oscar[cluster], args[0], false)
 		if err == nil {
@@ -67,6 +72,62 @@ func serviceLogsRemoveFunc(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// pruneServiceLogs removes the jobs selected by the "--older-than", "--status" and "--keep-last" flags
+func pruneServiceLogs(cmd *cobra.Command, c *cluster.Cluster, serviceName string) error {
+	selector := service.JobSelector{}
+	if olderThan, _ := cmd.Flags().GetString("older-than"); olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return fmt.Errorf("--older-than: %v", err)
+		}
+		selector.OlderThan = age
+	}
+	selector.Statuses, _ = cmd.Flags().GetStringSlice("status")
+	selector.KeepLast, _ = cmd.Flags().GetInt("keep-last")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	workers, _ := cmd.Flags().GetInt("parallel")
+	if workers < 1 {
+		return errors.New("--parallel must be greater than zero")
+	}
+
+	jobs, err := service.ListAllLogs(c, serviceName)
+	if err != nil {
+		return err
+	}
+	selected := selector.Select(jobs, time.Now())
+
+	if len(selected) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No jobs from service \"%s\" match the criteria\n", serviceName)
+		return nil
+	}
+
+	if dryRun {
+		for _, jobName := range selected {
+			fmt.Fprintf(cmd.OutOrStdout(), "Job \"%s\" (%s) would be removed\n", jobName, jobs[jobName].Status)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%d jobs from service \"%s\" would be removed (dry run)\n", len(selected), serviceName)
+		return nil
+	}
+
+	errs := service.RemoveJobs(cmd.Context(), c, serviceName, selected, workers)
+	for _, jobName := range selected {
+		if err, failed := errs[jobName]; failed {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%sjob \"%s\": %v\n", failureString, jobName, err)
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d jobs from service \"%s\" removed successfully\n", len(selected)-len(errs), serviceName)
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d jobs could not be removed", len(errs), len(selected))
+	}
+
+	return nil
+}
+
+// isLogsPruneMode reports whether the jobs to remove are selected by age, status or count
+func isLogsPruneMode(cmd *cobra.Command) bool {
+	return cmd.Flags().Changed("older-than") || cmd.Flags().Changed("status") || cmd.Flags().Changed("keep-last")
+}
+
 func checkServiceLogsRemoveArgs(cmd *cobra.Command, args []string) error {
 	err := cobra.MinimumNArgs(1)(cmd, args)
 	if err != nil {
@@ -75,11 +136,25 @@ func checkServiceLogsRemoveArgs(cmd *cobra.Command, args []string) error {
 
 	all, _ := cmd.Flags().GetBool("all")
 	succeeded, _ := cmd.Flags().GetBool("succeeded")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 	if all && succeeded {
 		return errors.New("only one of \"--all\" or \"--succeeded\" flags can be set")
 	}
 
+	if isLogsPruneMode(cmd) {
+		if keepLast, _ := cmd.Flags().GetInt("keep-last"); keepLast < 0 {
+			return errors.New("\"--keep-last\" cannot be negative")
+		}
+		if all || succeeded {
+			return errors.New("\"--all\" and \"--succeeded\" cannot be used together with \"--older-than\", \"--status\" or \"--keep-last\"")
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	}
+	if dryRun {
+		return errors.New("\"--dry-run\" can only be used together with \"--older-than\", \"--status\" or \"--keep-last\"")
+	}
+
 	if all || succeeded {
 		return cobra.ExactArgs(1)(cmd, args)
 	}
@@ -89,8 +164,14 @@ func checkServiceLogsRemoveArgs(cmd *cobra.Command, args []string) error {
 
 func makeServiceLogsRemoveCmd() *cobra.Command {
 	serviceLogsRemoveCmd := &cobra.Command{
-		Use:     "delete SERVICE_NAME {JOB_NAME... | --succeeded | --all}",
-		Short:   "Delete a service's job along with its logs",
+		Use:   "delete SERVICE_NAME {JOB_NAME... | --succeeded | --all | [--older-than DURATION] [--status STATUS] [--keep-last N]}",
+		Short: "Delete a service's job along with its logs",
+		Long: `Delete a service's job along with its logs.
+
+The jobs can also be selected by age with "--older-than", by status with "--status" and keeping
+the most recent ones with "--keep-last". When "--status" is not set only finished (Succeeded or
+Failed) jobs are selected. The jobs without timestamps are skipped by "--older-than" and
+"--keep-last", as their age is unknown. Use "--dry-run" to list the selected jobs without removing them.`,
 		Args:    checkServiceLogsRemoveArgs,
 		Aliases: []string{"d", "del", "remove", "rm"},
 		RunE:    serviceLogsRemoveFunc,
@@ -98,6 +179,11 @@ func makeServiceLogsRemoveCmd() *cobra.Command {
 
 	serviceLogsRemoveCmd.Flags().BoolP("all", "a", false, "remove all logs from the service")
 	serviceLogsRemoveCmd.Flags().BoolP("succeeded", "s", false, "remove succeeded logs from the service")
+	serviceLogsRemoveCmd.Flags().String("older-than", "", "remove the jobs created before the given duration ago, e.g. 12h or 7d")
+	serviceLogsRemoveCmd.Flags().StringSlice("status", []string{}, "remove the jobs with the given status (Pending, Running, Succeeded or Failed), multiple values can be specified by a comma-separated string")
+	serviceLogsRemoveCmd.Flags().Int("keep-last", 0, "keep the given number of most recent jobs")
+	serviceLogsRemoveCmd.Flags().Bool("dry-run", false, "list the selected jobs without removing them")
+	serviceLogsRemoveCmd.Flags().Int("parallel", 8, "maximum number of concurrent removals")
 
 	return serviceLogsRemoveCmd
 }
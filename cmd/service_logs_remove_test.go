@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newLogsPruneServer(t *testing.T, serviceName string) (*httptest.Server, func() []string) {
+	t.Helper()
+
+	now := time.Now().UTC()
+	jobs := map[string]struct {
+		status string
+		age    time.Duration
+	}{
+		"job-1": {"Succeeded", 10 * 24 * time.Hour},
+		"job-2": {"Failed", 9 * 24 * time.Hour},
+		"job-3": {"Succeeded", 8 * 24 * time.Hour},
+		"job-4": {"Running", 8 * 24 * time.Hour},
+		"job-5": {"Succeeded", 1 * time.Hour},
+		// Jobs without timestamps are reported with a zero age
+		"job-6": {"Succeeded", 0},
+	}
+
+	var mu sync.Mutex
+	removed := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/"+serviceName:
+			entries := []string{}
+			for name, job := range jobs {
+				if job.age == 0 {
+					entries = append(entries, fmt.Sprintf(`%q:{"status":%q}`, name, job.status))
+					continue
+				}
+				entries = append(entries, fmt.Sprintf(`%q:{"status":%q,"creation_time":%q}`, name, job.status, now.Add(-job.age).Format(time.RFC3339)))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jobs":{%s}}`, strings.Join(entries, ","))
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/system/logs/"+serviceName+"/"):
+			mu.Lock()
+			removed = append(removed, strings.TrimPrefix(r.URL.Path, "/system/logs/"+serviceName+"/"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		sorted := append([]string{}, removed...)
+		sort.Strings(sorted)
+		return sorted
+	}
+}
+
+func TestServiceLogsRemoveOlderThan(t *testing.T) {
+	server, removed := newLogsPruneServer(t, "demo")
+	configFile := writeConfigFile(t, "logs-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"logs", "remove", "demo",
+		"--older-than", "7d",
+		"--keep-last", "2",
+		"--parallel", "2",
+	)
+	if err != nil {
+		t.Fatalf("service logs remove returned error: %v", err)
+	}
+
+	// job-4 is running and job-3 and job-5 are the two most recent finished jobs
+	if got := removed(); strings.Join(got, ",") != "job-1,job-2" {
+		t.Fatalf("expected job-1 and job-2 to be removed, got %v", got)
+	}
+	if !strings.Contains(stdout, `2 jobs from service "demo" removed successfully`) {
+		t.Fatalf("unexpected output %q", stdout)
+	}
+}
+
+func TestServiceLogsRemoveDryRunByStatus(t *testing.T) {
+	server, removed := newLogsPruneServer(t, "demo")
+	configFile := writeConfigFile(t, "logs-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"logs", "remove", "demo",
+		"--status", "failed,running",
+		"--dry-run",
+	)
+	if err != nil {
+		t.Fatalf("service logs remove --dry-run returned error: %v", err)
+	}
+	if got := removed(); len(got) != 0 {
+		t.Fatalf("expected no jobs to be removed in a dry run, got %v", got)
+	}
+	if !strings.Contains(stdout, `Job "job-2" (Failed) would be removed`) || !strings.Contains(stdout, `Job "job-4" (Running) would be removed`) {
+		t.Fatalf("expected the selected jobs to be listed, got %q", stdout)
+	}
+	if strings.Contains(stdout, "job-1") {
+		t.Fatalf("unexpected job selected in %q", stdout)
+	}
+}
+
+func TestServiceLogsRemoveKeepLastSkipsJobsWithoutTimestamp(t *testing.T) {
+	server, removed := newLogsPruneServer(t, "demo")
+	configFile := writeConfigFile(t, "logs-cluster", server.URL)
+
+	if _, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"logs", "remove", "demo",
+		"--keep-last", "1",
+	); err != nil {
+		t.Fatalf("service logs remove returned error: %v", err)
+	}
+
+	// job-6 has no timestamp, so it can't be considered older than job-5
+	if got := removed(); strings.Join(got, ",") != "job-1,job-2,job-3" {
+		t.Fatalf("expected job-1, job-2 and job-3 to be removed, got %v", got)
+	}
+}
+
+func TestServiceLogsRemoveByStatusSelectsJobsWithoutTimestamp(t *testing.T) {
+	server, _ := newLogsPruneServer(t, "demo")
+	configFile := writeConfigFile(t, "logs-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"logs", "remove", "demo",
+		"--status", "succeeded",
+		"--dry-run",
+	)
+	if err != nil {
+		t.Fatalf("service logs remove --dry-run returned error: %v", err)
+	}
+	if !strings.Contains(stdout, `Job "job-6" (Succeeded) would be removed`) {
+		t.Fatalf("expected the job without timestamp to be selected by status, got %q", stdout)
+	}
+}
+
+func TestServiceLogsRemoveValidation(t *testing.T) {
+	configFile := writeConfigFile(t, "logs-cluster", "http://127.0.0.1:0")
+
+	testCases := [][]string{
+		{"--older-than", "7d", "--all"},
+		{"--keep-last", "3", "job-1"},
+		{"--dry-run", "--succeeded"},
+		{"--keep-last=-1"},
+	}
+	for _, flags := range testCases {
+		args := append([]string{"service", "--config", configFile, "logs", "remove", "demo"}, flags...)
+		if _, _, err := runCommand(t, args...); err == nil {
+			t.Fatalf("expected an error for %v", flags)
+		}
+	}
+}
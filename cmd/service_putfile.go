@@ -22,7 +22,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/grycap/oscar-cli/pkg/config"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/grycap/oscar-cli/pkg/storage"
 	"github.com/grycap/oscar/v3/pkg/types"
@@ -42,7 +41,7 @@ func servicePutFileFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
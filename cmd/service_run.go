@@ -22,17 +22,19 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/grycap/oscar-cli/pkg/config"
+	"github.com/grycap/oscar-cli/pkg/cluster"
 	"github.com/grycap/oscar-cli/pkg/service"
 	"github.com/spf13/cobra"
 )
 
 func serviceRunFunc(cmd *cobra.Command, args []string) error {
 	// Read the config file
-	conf, err := config.ReadConfig(configPath)
+	conf, err := readConfig()
 	if err != nil {
 		return err
 	}
@@ -54,10 +56,25 @@ func serviceRunFunc(cmd *cobra.Command, args []string) error {
 		// Error missing endpoint
 		return errors.New("you must specify a the cluster endpoint with the flag \"--endpoint\"")
 	}
+
+	opts, err := getRunOptions(cmd)
+	if err != nil {
+		return err
+	}
+	opts.Token = token
+	opts.Endpoint = endpoint
+	raw, _ := cmd.Flags().GetBool("raw")
+
 	// Parse input (only --input or --text-input are allowed) (AND one of them is required)
 	inputFile, _ := cmd.Flags().GetString("file-input")
 	textInput, _ := cmd.Flags().GetString("text-input")
 	outputFile, _ := cmd.Flags().GetString("output")
+	if isBatchMode(cmd) {
+		if outputFile != "" {
+			return errors.New("\"--output\" cannot be used together with \"--input-dir\", use \"--output-dir\" instead")
+		}
+		return serviceRunBatch(cmd, conf.Oscar[cluster], args[0], opts, raw)
+	}
 	if inputFile == "" && textInput == "" {
 		return errors.New("you must specify \"--file-input\" or \"--text-input\" flag")
 	}
@@ -67,100 +84,146 @@ func serviceRunFunc(cmd *cobra.Command, args []string) error {
 
 	var inputReader io.Reader = bytes.NewBufferString(textInput)
 
-	if inputFile != "" {
+	if inputFile == "-" {
+		// Read the input from stdin
+		inputReader = cmd.InOrStdin()
+	} else if inputFile != "" {
 		// Open the file
 		file, err := os.Open(inputFile)
-		defer file.Close()
 		if err != nil {
 			return fmt.Errorf("unable to read the file \"%s\"", inputFile)
 		}
+		defer file.Close()
 		// Set the file as the inputReader
 		inputReader = file
 	}
 
-	// Make pipe to encode file stream
-	reader, writer := io.Pipe()
-	encoder := base64.NewEncoder(base64.StdEncoding, writer)
+	// The input is base64 encoded unless --raw is set
+	if !raw {
+		inputReader = base64Reader(inputReader)
+	}
 
-	// Copy the file to the encoder in a goroutine to avoid blocking the execution
-	go func() {
-		_, err := io.Copy(encoder, inputReader)
-		encoder.Close()
-		if err != nil {
-			writer.CloseWithError(err)
-		}
-		writer.Close()
-	}()
 	// Make the request
-	resBody, err := service.RunService(conf.Oscar[cluster], args[0], token, endpoint, reader)
+	resBody, err := service.RunServiceWithOptions(conf.Oscar[cluster], args[0], inputReader, opts)
 	if err != nil {
 		return err
 	}
 	defer resBody.Close()
 
-	// Create temp file to store the result body
-	tmpfile, err := ioutil.TempFile("", "")
-	defer os.Remove(tmpfile.Name())
-	defer tmpfile.Close()
-	if err != nil {
-		return errors.New("unable to create a temporary file to store the result")
-	}
-	_, err = io.Copy(tmpfile, resBody)
-	if err != nil {
-		return errors.New("unable to copy the response")
-	}
-
-	// Decode the result body
-	tmpfile.Seek(0, 0)
-	decoder := base64.NewDecoder(base64.StdEncoding, tmpfile)
-
 	// Parse output (store file if --output is set)
-	var out *os.File
-
+	out := cmd.OutOrStdout()
 	if outputFile != "" {
-		// Create the file if --output is set
-		out, err = os.Create(outputFile)
+		file, err := os.Create(outputFile)
 		if err != nil {
 			return fmt.Errorf("unable to create the file \"%s\"", outputFile)
 		}
+		defer file.Close()
+		out = file
+	}
+
+	// Stream the response, decoding it unless --raw is set
+	if raw {
+		_, err = io.Copy(out, resBody)
 	} else {
-		// Create a temporary file
-		out, err = ioutil.TempFile("", "")
-		if err != nil {
-			return errors.New("unable to create a temporary file to decode the result")
+		err = copyDecodedResponse(out, resBody)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to copy the response: %v", err)
+	}
+
+	return nil
+}
+
+// getRunOptions returns the content type and headers of the request set with "--content-type" and "--header"
+func getRunOptions(cmd *cobra.Command) (service.RunOptions, error) {
+	opts := service.RunOptions{Headers: http.Header{}}
+	opts.ContentType, _ = cmd.Flags().GetString("content-type")
+
+	headers, _ := cmd.Flags().GetStringArray("header")
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return opts, fmt.Errorf("the header \"%s\" is not valid, it must have the form \"KEY: VALUE\"", header)
 		}
-		defer os.Remove(out.Name())
+		opts.Headers.Add(strings.TrimSpace(key), strings.TrimSpace(value))
 	}
-	defer out.Close()
 
-	// Copy the decoder stream into out
-	_, err = io.Copy(out, decoder)
+	return opts, nil
+}
+
+// copyDecodedResponse copies the base64 decoded response into w, or the response untouched if it can't be decoded.
+// The response is stored in a temporary file, so it's checked as a whole without loading it in memory
+func copyDecodedResponse(w io.Writer, r io.Reader) error {
+	tmpfile, err := os.CreateTemp("", "oscar-cli-response-")
 	if err != nil {
-		// If resBody can't be decoded copy it directly
-		// Seek tmpfile and out to start from the beginning
-		tmpfile.Seek(0, 0)
-		out.Seek(0, 0)
-		_, err = io.Copy(out, tmpfile)
+		return errors.New("unable to create a temporary file to store the response")
+	}
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	if _, err := io.Copy(tmpfile, r); err != nil {
+		return err
+	}
+
+	// Check that the whole response can be decoded before writing anything
+	if _, err := tmpfile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, decodeErr := io.Copy(io.Discard, base64.NewDecoder(base64.StdEncoding, tmpfile))
+	if _, err := tmpfile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if decodeErr != nil {
+		_, err = io.Copy(w, tmpfile)
+		return err
+	}
+	_, err = io.Copy(w, base64.NewDecoder(base64.StdEncoding, tmpfile))
+	return err
+}
+
+// serviceRunBatch invokes the service once per file of "--input-dir", storing the outputs in "--output-dir"
+func serviceRunBatch(cmd *cobra.Command, c *cluster.Cluster, serviceName string, opts service.RunOptions, raw bool) error {
+	if err := checkBatchFlags(cmd); err != nil {
+		return err
+	}
+	outputDir, _ := cmd.Flags().GetString("output-dir")
+	if outputDir == "" {
+		return errors.New("you must specify \"--output-dir\" together with \"--input-dir\"")
+	}
+
+	return runBatch(cmd, func(input io.Reader, relativePath string) error {
+		resBody, err := service.RunServiceWithOptions(c, serviceName, input, opts)
 		if err != nil {
-			return errors.New("unable to copy the response")
+			return err
 		}
-	}
+		defer resBody.Close()
 
-	if outputFile == "" {
-		// Copy out to stdout
-		out.Seek(0, 0)
-		_, err = io.Copy(os.Stdout, out)
+		outputPath := filepath.Join(outputDir, relativePath)
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			return fmt.Errorf("unable to create the directory \"%s\"", filepath.Dir(outputPath))
+		}
+		file, err := os.Create(outputPath)
 		if err != nil {
-			return errors.New("unable to print the result")
+			return fmt.Errorf("unable to create the file \"%s\"", outputPath)
 		}
-	}
+		defer file.Close()
 
-	return nil
+		if raw {
+			_, err = io.Copy(file, resBody)
+		} else {
+			err = copyDecodedResponse(file, resBody)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to copy the response: %v", err)
+		}
+		return nil
+	})
 }
 
 func makeServiceRunCmd() *cobra.Command {
 	serviceRunCmd := &cobra.Command{
-		Use:     "run SERVICE_NAME {--file-input | --text-input}",
+		Use:     "run SERVICE_NAME {--file-input | --text-input | --input-dir --output-dir}",
 		Short:   "Invoke a service synchronously (a Serverless backend in the cluster is required)",
 		Args:    cobra.ExactArgs(1),
 		Aliases: []string{"invoke", "r"},
@@ -170,9 +233,13 @@ func makeServiceRunCmd() *cobra.Command {
 	serviceRunCmd.Flags().StringP("cluster", "c", "", "set the cluster")
 	serviceRunCmd.Flags().StringP("endpoint", "e", "", "endpoint of a non registered cluster")
 	serviceRunCmd.Flags().StringP("token", "t", "", "token of the service")
-	serviceRunCmd.Flags().StringP("file-input", "f", "", "input file for the request")
+	serviceRunCmd.Flags().StringP("file-input", "f", "", "input file for the request, \"-\" reads the input from stdin")
 	serviceRunCmd.Flags().StringP("text-input", "i", "", "text input string for the request")
 	serviceRunCmd.Flags().StringP("output", "o", "", "file path to store the output")
+	serviceRunCmd.Flags().Bool("raw", false, "send the input and print the output untouched, without base64 encoding")
+	serviceRunCmd.Flags().String("content-type", "", "content type of the input")
+	serviceRunCmd.Flags().StringArray("header", []string{}, "header to add to the request in the form \"KEY: VALUE\", can be specified multiple times")
+	addBatchFlags(serviceRunCmd, true)
 
 	return serviceRunCmd
 }
@@ -10,7 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/grycap/oscar/v3/pkg/types"
 )
@@ -215,3 +217,220 @@ func TestServiceRunCommandInputValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceRunCommandBatch(t *testing.T) {
+	originalDelay := batchRetryDelay
+	batchRetryDelay = time.Millisecond
+	defer func() { batchRetryDelay = originalDelay }()
+
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/run/echo" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		input, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			t.Errorf("decoding input: %v", err)
+		}
+
+		mu.Lock()
+		attempts[string(input)]++
+		attempt := attempts[string(input)]
+		mu.Unlock()
+
+		switch {
+		case string(input) == "flaky" && attempt == 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case string(input) == "broken":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "invalid input")
+		default:
+			fmt.Fprint(w, base64.StdEncoding.EncodeToString([]byte("OUT:"+string(input))))
+		}
+	}))
+	defer server.Close()
+
+	inputDir := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "alpha", "flaky.txt": "flaky", filepath.Join("sub", "b.txt"): "beta", "broken.txt": "broken"} {
+		path := filepath.Join(inputDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating input dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing input: %v", err)
+		}
+	}
+	outputDir := filepath.Join(t.TempDir(), "results")
+	configFile := writeConfigFile(t, "run-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"run", "echo",
+		"--input-dir", inputDir,
+		"--output-dir", outputDir,
+		"--parallel", "2",
+	)
+	if err == nil || err.Error() != "1 of 4 invocations failed" {
+		t.Fatalf("expected the broken input to fail, got %v", err)
+	}
+	if !strings.Contains(stdout, "3 succeeded, 1 failed") {
+		t.Fatalf("expected a summary of the invocations, got %q", stdout)
+	}
+
+	for name, expected := range map[string]string{"a.txt": "OUT:alpha", "flaky.txt": "OUT:flaky", filepath.Join("sub", "b.txt"): "OUT:beta"} {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil || string(content) != expected {
+			t.Fatalf("unexpected output for %s: %q, %v", name, content, err)
+		}
+	}
+	if attempts["flaky"] != 2 || attempts["broken"] != 1 {
+		t.Fatalf("expected only the transient failure to be retried, got %v", attempts)
+	}
+}
+
+func TestServiceRunCommandBatchValidation(t *testing.T) {
+	configFile := writeConfigFile(t, "run-cluster", "http://127.0.0.1:0")
+	inputDir := t.TempDir()
+
+	testCases := [][]string{
+		{"--input-dir", inputDir},
+		{"--input-dir", inputDir, "--output-dir", inputDir, "--text-input", "data"},
+		{"--input-dir", inputDir, "--output-dir", inputDir, "--output", "out.txt"},
+		{"--input-dir", inputDir, "--output-dir", inputDir, "--parallel", "0"},
+	}
+	for _, flags := range testCases {
+		args := append([]string{"service", "--config", configFile, "run", "echo"}, flags...)
+		if _, _, err := runCommand(t, args...); err == nil {
+			t.Fatalf("expected an error for %v", flags)
+		}
+	}
+}
+
+func TestServiceRunCommandRawStdin(t *testing.T) {
+	payload := []byte{0x00, 0xff, 0x10, 'b', 'i', 'n'}
+	var (
+		receivedBody    []byte
+		receivedHeaders http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/run/echo" {
+			http.NotFound(w, r)
+			return
+		}
+		receivedHeaders = r.Header.Clone()
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Write(receivedBody)
+	}))
+	defer server.Close()
+
+	stdinFile := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(stdinFile, payload, 0o600); err != nil {
+		t.Fatalf("writing stdin file: %v", err)
+	}
+	stdin, err := os.Open(stdinFile)
+	if err != nil {
+		t.Fatalf("opening stdin file: %v", err)
+	}
+	defer stdin.Close()
+	originalStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = originalStdin }()
+
+	outputFile := filepath.Join(t.TempDir(), "out.bin")
+	configFile := writeConfigFile(t, "run-cluster", server.URL)
+
+	_, _, err = runCommand(t,
+		"service", "--config", configFile,
+		"run", "echo",
+		"--file-input", "-",
+		"--raw",
+		"--content-type", "application/octet-stream",
+		"--header", "X-Trace: abc",
+		"--header", "X-Trace: def",
+		"--output", outputFile,
+	)
+	if err != nil {
+		t.Fatalf("service run --raw returned error: %v", err)
+	}
+	if string(receivedBody) != string(payload) {
+		t.Fatalf("expected the input to be sent untouched, got %q", receivedBody)
+	}
+	if receivedHeaders.Get("Content-Type") != "application/octet-stream" {
+		t.Fatalf("unexpected content type %q", receivedHeaders.Get("Content-Type"))
+	}
+	if traces := receivedHeaders.Values("X-Trace"); len(traces) != 2 || traces[0] != "abc" || traces[1] != "def" {
+		t.Fatalf("unexpected custom headers %v", traces)
+	}
+	output, err := os.ReadFile(outputFile)
+	if err != nil || string(output) != string(payload) {
+		t.Fatalf("expected the response to be stored untouched, got %q, %v", output, err)
+	}
+}
+
+func TestServiceRunCommandLargeResponse(t *testing.T) {
+	expected := strings.Repeat("0123456789abcdef", 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/run/echo" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, base64.StdEncoding.EncodeToString([]byte(expected)))
+	}))
+	defer server.Close()
+	configFile := writeConfigFile(t, "run-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"run", "echo",
+		"--text-input", "data",
+	)
+	if err != nil {
+		t.Fatalf("service run returned error: %v", err)
+	}
+	if stdout != expected {
+		t.Fatalf("expected the decoded response of %d bytes, got %d bytes", len(expected), len(stdout))
+	}
+}
+
+func TestServiceRunCommandUndecodableResponse(t *testing.T) {
+	// The beginning of the response is valid base64, but the rest of it isn't
+	expected := strings.Repeat("QUJD", 2048) + "\nnot base64: {\"status\": \"ok\"}\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/run/echo" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, expected)
+	}))
+	defer server.Close()
+	configFile := writeConfigFile(t, "run-cluster", server.URL)
+
+	stdout, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"run", "echo",
+		"--text-input", "data",
+	)
+	if err != nil {
+		t.Fatalf("service run returned error: %v", err)
+	}
+	if stdout != expected {
+		t.Fatalf("expected the response to be printed untouched, got %d bytes", len(stdout))
+	}
+}
+
+func TestServiceRunCommandInvalidHeader(t *testing.T) {
+	configFile := writeConfigFile(t, "run-cluster", "http://127.0.0.1:0")
+
+	_, _, err := runCommand(t,
+		"service", "--config", configFile,
+		"run", "echo",
+		"--text-input", "data",
+		"--header", "missing-separator",
+	)
+	if err == nil || !strings.Contains(err.Error(), "missing-separator") {
+		t.Fatalf("expected an error for the invalid header, got %v", err)
+	}
+}
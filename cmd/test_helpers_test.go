@@ -103,3 +103,19 @@ func writeRawConfig(t *testing.T, content string) string {
 	}
 	return configPath
 }
+
+func writeFDLFile(t *testing.T, content string, scripts ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, script), []byte("#!/bin/bash\necho "+script+"\n"), 0o700); err != nil {
+			t.Fatalf("writing script %s: %v", script, err)
+		}
+	}
+	fdlPath := filepath.Join(dir, "fdl.yaml")
+	if err := os.WriteFile(fdlPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing fdl: %v", err)
+	}
+	return fdlPath
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// ClusterAPI gets the information of a cluster
+type ClusterAPI interface {
+	GetInfo(ctx context.Context) (types.Info, error)
+	GetConfig(ctx context.Context) (types.Config, error)
+}
+
+// ServiceAPI manages and invokes the services of a cluster
+type ServiceAPI interface {
+	GetService(ctx context.Context, name string) (*types.Service, error)
+	ListServices(ctx context.Context) ([]*types.Service, error)
+	CreateService(ctx context.Context, svc *types.Service) error
+	UpdateService(ctx context.Context, svc *types.Service) error
+	DeleteService(ctx context.Context, name string) error
+	RunService(ctx context.Context, name string, input io.Reader, opts service.RunOptions) (io.ReadCloser, error)
+	JobService(ctx context.Context, name string, input io.Reader) (io.ReadCloser, error)
+}
+
+// LogsAPI manages the jobs (logs) of the services of a cluster
+type LogsAPI interface {
+	ListJobs(ctx context.Context, svcName string) (map[string]*types.JobInfo, error)
+	GetJob(ctx context.Context, svcName, jobName string) (*types.JobInfo, error)
+	GetJobLogs(ctx context.Context, svcName, jobName string, timestamps bool) (string, error)
+	DeleteJob(ctx context.Context, svcName, jobName string) error
+	DeleteJobs(ctx context.Context, svcName string, all bool) error
+}
+
+// StorageAPI manages the buckets and files of a cluster
+type StorageAPI interface {
+	ListBuckets(ctx context.Context) ([]*storage.BucketInfo, error)
+	CreateBucket(ctx context.Context, bucket *storage.BucketInfo) error
+	DeleteBucket(ctx context.Context, name string) error
+	ListBucketObjects(ctx context.Context, bucketName string, opts *storage.BucketListOptions) (*storage.BucketListResult, error)
+	GetFile(ctx context.Context, svc *types.Service, providerString, remotePath, localPath string) error
+	PutFile(ctx context.Context, svc *types.Service, providerString, localPath, remotePath string) error
+}
+
+// API is the OSCAR API of a cluster, implemented by Client. Code depending on it can be tested with mocks
+type API interface {
+	ClusterAPI
+	ServiceAPI
+	LogsAPI
+	StorageAPI
+}
@@ -0,0 +1,201 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// Client is a long-lived client of the OSCAR API of a cluster. All its requests share the same HTTP client,
+// so the connections and the credentials (e.g. OIDC tokens) are reused. It's safe for concurrent use
+type Client struct {
+	cluster *cluster.Cluster
+	http    *http.Client
+}
+
+var _ API = (*Client)(nil)
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	roundTripper http.RoundTripper
+	timeout      time.Duration
+}
+
+// WithRoundTripper sends the requests through the given round tripper instead of a new http.Transport,
+// e.g. to share a transport among clients or to intercept the requests. The credentials and the retries
+// of the cluster are still added on top of it
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(o *options) {
+		o.roundTripper = rt
+	}
+}
+
+// WithTimeout sets the timeout of each request, overriding the one of the cluster configuration
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// New returns a client for the cluster. The credentials are checked when it's created
+func New(c *cluster.Cluster, opts ...Option) (*Client, error) {
+	if c == nil {
+		return nil, errors.New("cluster configuration not provided")
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	httpClient, err := c.NewHTTPClient(o.roundTripper)
+	if err != nil {
+		return nil, err
+	}
+	if o.timeout > 0 {
+		httpClient.Timeout = o.timeout
+	}
+
+	return &Client{
+		cluster: c.WithHTTPClient(httpClient),
+		http:    httpClient,
+	}, nil
+}
+
+// Cluster returns the configuration of the cluster, bound to the HTTP client of the Client so it can be
+// used with the functions of the service and storage packages
+func (c *Client) Cluster() *cluster.Cluster {
+	return c.cluster
+}
+
+// HTTPClient returns the HTTP client shared by all the requests
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// GetInfo returns the info of the cluster
+func (c *Client) GetInfo(ctx context.Context) (types.Info, error) {
+	return c.cluster.GetClusterInfoWithContext(ctx)
+}
+
+// GetConfig returns the config of the cluster
+func (c *Client) GetConfig(ctx context.Context) (types.Config, error) {
+	return c.cluster.GetClusterConfigWithContext(ctx)
+}
+
+// GetService returns the definition of a service
+func (c *Client) GetService(ctx context.Context, name string) (*types.Service, error) {
+	return service.GetServiceWithContext(ctx, c.cluster, name)
+}
+
+// ListServices returns the services of the cluster
+func (c *Client) ListServices(ctx context.Context) ([]*types.Service, error) {
+	return service.ListServicesWithContext(ctx, c.cluster)
+}
+
+// CreateService creates a service
+func (c *Client) CreateService(ctx context.Context, svc *types.Service) error {
+	return service.ApplyServiceWithContext(ctx, svc, c.cluster, http.MethodPost)
+}
+
+// UpdateService updates an existing service
+func (c *Client) UpdateService(ctx context.Context, svc *types.Service) error {
+	return service.ApplyServiceWithContext(ctx, svc, c.cluster, http.MethodPut)
+}
+
+// DeleteService deletes a service
+func (c *Client) DeleteService(ctx context.Context, name string) error {
+	return service.RemoveServiceWithContext(ctx, c.cluster, name)
+}
+
+// RunService invokes a service synchronously, returning the response body that must be closed by the caller.
+// The input is sent untouched, so it must be base64 encoded unless the service handles raw requests
+func (c *Client) RunService(ctx context.Context, name string, input io.Reader, opts service.RunOptions) (io.ReadCloser, error) {
+	opts.Context = ctx
+	return service.RunServiceWithOptions(c.cluster, name, input, opts)
+}
+
+// JobService invokes a service asynchronously, returning the response body that must be closed by the caller
+func (c *Client) JobService(ctx context.Context, name string, input io.Reader) (io.ReadCloser, error) {
+	return service.JobServiceWithContext(ctx, c.cluster, name, "", "", input)
+}
+
+// ListJobs returns the jobs of a service, going through all the pages
+func (c *Client) ListJobs(ctx context.Context, svcName string) (map[string]*types.JobInfo, error) {
+	return service.ListAllLogsWithContext(ctx, c.cluster, svcName)
+}
+
+// GetJob returns the info of a service's job, or service.ErrJobNotFound if it doesn't exist
+func (c *Client) GetJob(ctx context.Context, svcName, jobName string) (*types.JobInfo, error) {
+	return service.GetJobInfoWithContext(ctx, c.cluster, svcName, jobName)
+}
+
+// GetJobLogs returns the logs of a service's job
+func (c *Client) GetJobLogs(ctx context.Context, svcName, jobName string, timestamps bool) (string, error) {
+	return service.GetLogsWithContext(ctx, c.cluster, svcName, jobName, timestamps)
+}
+
+// DeleteJob deletes a service's job and its logs
+func (c *Client) DeleteJob(ctx context.Context, svcName, jobName string) error {
+	return service.RemoveLogWithContext(ctx, c.cluster, svcName, jobName)
+}
+
+// DeleteJobs deletes the finished jobs of a service, or all of them if all is set
+func (c *Client) DeleteJobs(ctx context.Context, svcName string, all bool) error {
+	return service.RemoveLogsWithContext(ctx, c.cluster, svcName, all)
+}
+
+// ListBuckets returns the buckets of the cluster MinIO provider
+func (c *Client) ListBuckets(ctx context.Context) ([]*storage.BucketInfo, error) {
+	return storage.ListBucketsWithContext(ctx, c.cluster)
+}
+
+// CreateBucket creates a bucket in the cluster MinIO provider
+func (c *Client) CreateBucket(ctx context.Context, bucket *storage.BucketInfo) error {
+	return storage.CreateBucketWithContext(ctx, c.cluster, bucket)
+}
+
+// DeleteBucket deletes a bucket of the cluster MinIO provider
+func (c *Client) DeleteBucket(ctx context.Context, name string) error {
+	return storage.DeleteBucketWithContext(ctx, c.cluster, name)
+}
+
+// ListBucketObjects lists the objects of a bucket according to the options, which can be nil
+func (c *Client) ListBucketObjects(ctx context.Context, bucketName string, opts *storage.BucketListOptions) (*storage.BucketListResult, error) {
+	return storage.ListBucketObjectsWithOptionsContext(ctx, c.cluster, bucketName, opts)
+}
+
+// GetFile downloads a file from a storage provider of the service
+func (c *Client) GetFile(ctx context.Context, svc *types.Service, providerString, remotePath, localPath string) error {
+	return storage.GetFileWithServiceContext(ctx, c.cluster, svc, providerString, remotePath, localPath, &storage.TransferOption{})
+}
+
+// PutFile uploads a file to a storage provider of the service
+func (c *Client) PutFile(ctx context.Context, svc *types.Service, providerString, localPath, remotePath string) error {
+	return storage.PutFileWithServiceContext(ctx, c.cluster, svc, providerString, localPath, remotePath, &storage.TransferOption{})
+}
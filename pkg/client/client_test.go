@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+type recordingRoundTripper struct {
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientSharesRoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/system/info":
+			json.NewEncoder(w).Encode(types.Info{Version: "3.0.0"})
+		case "/system/services":
+			json.NewEncoder(w).Encode([]*types.Service{{Name: "demo"}})
+		case "/system/services/demo":
+			json.NewEncoder(w).Encode(types.Service{Name: "demo"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rt := &recordingRoundTripper{}
+	c, err := New(&cluster.Cluster{Endpoint: server.URL, AuthUser: "user", AuthPassword: "pass"}, WithRoundTripper(rt))
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	ctx := context.Background()
+	info, err := c.GetInfo(ctx)
+	if err != nil || info.Version != "3.0.0" {
+		t.Fatalf("unexpected info %+v, %v", info, err)
+	}
+	services, err := c.ListServices(ctx)
+	if err != nil || len(services) != 1 {
+		t.Fatalf("unexpected services %v, %v", services, err)
+	}
+	svc, err := c.GetService(ctx, "demo")
+	if err != nil || svc.Name != "demo" {
+		t.Fatalf("unexpected service %+v, %v", svc, err)
+	}
+
+	if len(rt.requests) != 3 {
+		t.Fatalf("expected every request to go through the round tripper, got %d", len(rt.requests))
+	}
+	// Functions of the other packages also reuse the client when given its cluster
+	if client, err := c.Cluster().GetClientSafe(); err != nil || client != c.HTTPClient() {
+		t.Fatalf("expected the cluster to be bound to the shared client, got %v, %v", client, err)
+	}
+}
+
+func TestClientHonoursContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	c, err := New(&cluster.Cluster{Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetJobLogs(ctx, "demo", "job", false); err == nil {
+		t.Fatal("expected an error for the cancelled context")
+	}
+}
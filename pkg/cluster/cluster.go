@@ -17,7 +17,7 @@ limitations under the License.
 package cluster
 
 import (
-	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -27,9 +27,9 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/grycap/oscar/v3/pkg/types"
 	"github.com/indigo-dc/liboidcagent-go"
 )
@@ -38,6 +38,9 @@ const infoPath = "/system/info"
 const configPath = "/system/config"
 const _DEFAULT_TIMEOUT = 20
 
+// oidcAgentMinValidPeriod is the minimum validity in seconds of the tokens requested to oidc-agent
+const oidcAgentMinValidPeriod = 600
+
 var (
 	// ErrParsingEndpoint error message for cluster endpoint parsing
 	ErrParsingEndpoint = errors.New("error parsing the cluster endpoint, please check that you have typed it correctly")
@@ -45,6 +48,18 @@ var (
 	ErrMakingRequest = errors.New("error making the request")
 	// ErrSendingRequest error message for sending requests
 	ErrSendingRequest = errors.New("unable to communicate with the cluster, please check that the endpoint is well typed and accessible")
+	// ErrNotFound error message for resources that don't exist in the cluster
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized error message for requests with missing or invalid credentials
+	ErrUnauthorized = errors.New("invalid credentials")
+	// ErrForbidden error message for requests not allowed to the user
+	ErrForbidden = errors.New("forbidden")
+	// ErrConflict error message for requests that conflict with existing resources
+	ErrConflict = errors.New("conflict")
+	// ErrServiceNotReady error message for services that can't handle requests yet
+	ErrServiceNotReady = errors.New("the service is not ready yet, please wait until it's ready or check if something failed")
+	// ErrRefreshTokenExpired error message for OIDC refresh tokens that have expired or have been revoked
+	ErrRefreshTokenExpired = errors.New("the OIDC refresh token has expired or has been revoked, please log in again with \"oscar-cli cluster login\" or update it in the cluster configuration")
 )
 
 type RefreshToken struct {
@@ -71,6 +86,7 @@ type ResponseRefreshToken struct {
 	NotBeforePolicy  int    `json:"not-before-policy"`
 	SessionState     string `json:"session_state"`
 	Scope            string `json:"scope"`
+	RefreshToken     string `json:"refresh_token"`
 }
 
 // Cluster defines the configuration of an OSCAR cluster
@@ -83,6 +99,19 @@ type Cluster struct {
 	SSLVerify        bool   `json:"ssl_verify"`
 	Memory           string `json:"memory"`
 	LogLevel         string `json:"log_level"`
+	// Timeout of the requests to the cluster in seconds, 20 if not set
+	Timeout int `json:"timeout,omitempty"`
+	// Retry policy of the requests to the cluster, the failed requests are retried up to 3 times if not set
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// Issuer and client ID used to refresh the OIDC tokens, taken from the refresh token claims if not set
+	OIDCIssuer   string `json:"oidc_issuer,omitempty"`
+	OIDCClientID string `json:"oidc_client_id,omitempty"`
+	// Scope requested when refreshing the OIDC tokens, taken from the refresh token claims if not set
+	OIDCScope string `json:"oidc_scope,omitempty"`
+	// httpClient is shared by all the requests to the cluster, if set
+	httpClient *http.Client
+	// refreshTokenHandler is called when the OIDC provider rotates the refresh token
+	refreshTokenHandler func(refreshToken string) error
 }
 
 type basicAuthRoundTripper struct {
@@ -92,57 +121,120 @@ type basicAuthRoundTripper struct {
 }
 
 type tokenRoundTripper struct {
-	token     string
+	source    *cachedTokenSource
 	transport http.RoundTripper
 }
 
 // RoundTrip function to implement the RoundTripper interface adding basic auth headers
 func (bart *basicAuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	// Add basic auth to requests
+	req = req.Clone(req.Context())
 	req.SetBasicAuth(bart.username, bart.password)
 	return bart.transport.RoundTrip(req)
 }
 
 // RoundTrip function to implement the RoundTripper interface adding a bearer token
 func (trt *tokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := trt.source.Token()
+	if err != nil {
+		return nil, err
+	}
 	// Add bearer token to requests
-	req.Header.Add("Authorization", "Bearer "+trt.token)
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
 	return trt.transport.RoundTrip(req)
 }
 
+// WithHTTPClient returns a copy of the cluster that sends all its requests through the given client,
+// instead of creating a new one for each request
+func (cluster *Cluster) WithHTTPClient(client *http.Client) *Cluster {
+	bound := *cluster
+	bound.httpClient = client
+	return &bound
+}
+
 // GetClientSafe returns an HTTP client to communicate with the cluster without exiting on errors.
+// The timeout in seconds can be set as argument, otherwise the one of the cluster is used
 func (cluster *Cluster) GetClientSafe(args ...int) (*http.Client, error) {
+	client := cluster.httpClient
+	if client == nil {
+		var err error
+		client, err = cluster.NewHTTPClient(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(args) != 0 {
+		// Copy the client to keep sharing its transport
+		withTimeout := *client
+		withTimeout.Timeout = time.Second * time.Duration(args[0])
+		client = &withTimeout
+	}
+
+	return client, nil
+}
+
+// NewHTTPClient returns a new HTTP client to communicate with the cluster, adding the credentials and retrying
+// the transient failures. The requests are sent through the given round tripper, or a new transport if nil
+func (cluster *Cluster) NewHTTPClient(base http.RoundTripper) (*http.Client, error) {
 	timeout := _DEFAULT_TIMEOUT
+	if cluster.Timeout > 0 {
+		timeout = cluster.Timeout
+	}
 
-	var transport http.RoundTripper = &http.Transport{
-		// Enable/disable ssl verification
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !cluster.SSLVerify},
+	var transport http.RoundTripper = base
+	if transport == nil {
+		transport = &http.Transport{
+			// Enable/disable ssl verification
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: !cluster.SSLVerify},
+		}
+	}
+
+	// Retry the transient failures below the auth round trippers, so retried requests keep the auth headers
+	transport, err := cluster.newRetryRoundTripper(transport)
+	if err != nil {
+		return nil, err
 	}
 
 	if cluster.OIDCAccountName != "" {
 		// Get token from OIDC Agent
-		token, err := liboidcagent.GetAccessToken(liboidcagent.TokenRequest{
-			ShortName:       cluster.OIDCAccountName,
-			MinValidPeriod:  600,
-			Scopes:          []string{},
-			ApplicationHint: "OSCAR-CLI",
+		source := newCachedTokenSource(func() (string, time.Time, error) {
+			token, err := liboidcagent.GetAccessToken(liboidcagent.TokenRequest{
+				ShortName:       cluster.OIDCAccountName,
+				MinValidPeriod:  oidcAgentMinValidPeriod,
+				Scopes:          []string{},
+				ApplicationHint: "OSCAR-CLI",
+			})
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("unable to get the OIDC token, please check your oidc-agent configuration: %w", err)
+			}
+			// The agent returns tokens valid for at least the requested period
+			return token, time.Now().Add(oidcAgentMinValidPeriod * time.Second), nil
 		})
-
-		if err != nil {
-			return nil, fmt.Errorf("unable to get the OIDC token, please check your oidc-agent configuration: %w", err)
+		// Get the first token to report the errors when creating the client
+		if _, err := source.Token(); err != nil {
+			return nil, err
 		}
 
 		transport = &tokenRoundTripper{
-			token:     token,
+			source:    source,
 			transport: transport,
 		}
 	} else if cluster.OIDCRefreshToken != "" {
-		accessToken, err := cluster.getAccessToken()
-		if err != nil {
-			return nil, fmt.Errorf("unable to get the OIDC token from refresh token, please check your configuration: %w", err)
+		source := newCachedTokenSource(func() (string, time.Time, error) {
+			token, expiry, err := cluster.getAccessToken()
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("unable to get the OIDC token from refresh token, please check your configuration: %w", err)
+			}
+			return token, expiry, nil
+		})
+		if _, err := source.Token(); err != nil {
+			return nil, err
 		}
+
 		transport = &tokenRoundTripper{
-			token:     accessToken,
+			source:    source,
 			transport: transport,
 		}
 	} else {
@@ -154,10 +246,6 @@ func (cluster *Cluster) GetClientSafe(args ...int) (*http.Client, error) {
 		}
 	}
 
-	if len(args) != 0 {
-		timeout = args[0]
-	}
-
 	return &http.Client{
 		Transport: transport,
 		Timeout:   time.Second * time.Duration(timeout),
@@ -176,13 +264,18 @@ func (cluster *Cluster) GetClient(args ...int) *http.Client {
 
 // GetClusterInfo returns info from an OSCAR cluster
 func (cluster *Cluster) GetClusterInfo() (info types.Info, err error) {
+	return cluster.GetClusterInfoWithContext(context.Background())
+}
+
+// GetClusterInfoWithContext returns info from an OSCAR cluster honoring the provided context
+func (cluster *Cluster) GetClusterInfoWithContext(ctx context.Context) (info types.Info, err error) {
 	getInfoURL, err := url.Parse(cluster.Endpoint)
 	if err != nil {
 		return info, ErrParsingEndpoint
 	}
 	getInfoURL.Path = path.Join(getInfoURL.Path, infoPath)
 
-	req, err := http.NewRequest(http.MethodGet, getInfoURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, getInfoURL.String(), nil)
 	if err != nil {
 		return info, ErrMakingRequest
 	}
@@ -210,13 +303,18 @@ func (cluster *Cluster) GetClusterInfo() (info types.Info, err error) {
 
 // GetClusterConfig returns the config of an OSCAR cluster
 func (cluster *Cluster) GetClusterConfig() (cfg types.Config, err error) {
+	return cluster.GetClusterConfigWithContext(context.Background())
+}
+
+// GetClusterConfigWithContext returns the config of an OSCAR cluster honoring the provided context
+func (cluster *Cluster) GetClusterConfigWithContext(ctx context.Context) (cfg types.Config, err error) {
 	getConfigURL, err := url.Parse(cluster.Endpoint)
 	if err != nil {
 		return cfg, ErrParsingEndpoint
 	}
 	getConfigURL.Path = path.Join(getConfigURL.Path, configPath)
 
-	req, err := http.NewRequest(http.MethodGet, getConfigURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, getConfigURL.String(), nil)
 	if err != nil {
 		return cfg, ErrMakingRequest
 	}
@@ -242,76 +340,81 @@ func (cluster *Cluster) GetClusterConfig() (cfg types.Config, err error) {
 	return cfg, nil
 }
 
-// CheckStatusCode checks if a cluster response is valid and returns an appropriate error if not
+// APIError is returned by CheckStatusCode for the unsuccessful responses of the cluster.
+// It matches with errors.Is the sentinel error of its status code (ErrNotFound, ErrUnauthorized...), if any
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Method and URL of the failed request, empty if unknown
+	Method string
+	URL    string
+	// Body is the raw response body
+	Body string
+	// Message describes the error, taken from the response body unless the status has a specific message
+	Message string
+	// sentinel is the error matched by errors.Is for the status code, if any
+	sentinel error
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.sentinel
+}
+
+// CheckStatusCode checks if a cluster response is valid and returns an *APIError if not
 func CheckStatusCode(res *http.Response) error {
 	if res.StatusCode >= 200 && res.StatusCode <= 204 {
 		return nil
 	}
-	if res.StatusCode == 401 {
-		return errors.New("invalid credentials")
-	}
-	if res.StatusCode == 404 {
-		return errors.New("not found")
-	}
-	if res.StatusCode == 502 {
-		return errors.New("the service is not ready yet, please wait until it's ready or check if something failed")
+
+	apiErr := &APIError{StatusCode: res.StatusCode}
+	if res.Request != nil && res.Request.URL != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.URL = res.Request.URL.Redacted()
 	}
-	// Create an error from the failed response body
+
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return fmt.Errorf("cannot read the response: %v", err)
 	}
-	return errors.New(string(body))
-}
-
-func (cluster *Cluster) getAccessToken() (string, error) {
-	token, _ := jwt.Parse(cluster.OIDCRefreshToken, func(token *jwt.Token) (interface{}, error) {
-		return []byte("AllYourBase"), nil
-	})
-	iss, err := token.Claims.GetIssuer()
-	if err != nil {
-		fmt.Println(err)
-	}
-	url := iss + "/protocol/openid-connect/token"
-	if err != nil {
-		fmt.Println(err)
-	}
-	var scope string
-	var clientId string
-	//client_id := token.Claims.
-	if str, ok := token.Claims.(jwt.MapClaims); ok {
-		scope = str["scope"].(string)
-		clientId = str["azp"].(string)
-	} else {
-		fmt.Println("error")
-	}
-
-	jsonBody := []byte("grant_type=refresh_token&refresh_token=" +
-		cluster.OIDCRefreshToken +
-		"&client_id=" + clientId + "&scope=" + scope)
-
-	bodyReader := bytes.NewReader(jsonBody)
-	req, err := http.NewRequest(http.MethodPost, url, bodyReader)
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	if err != nil {
-		return "", fmt.Errorf("error at new request: %v", err)
+	apiErr.Body = string(body)
+	apiErr.Message = parseErrorMessage(body)
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		apiErr.sentinel = ErrUnauthorized
+		apiErr.Message = ErrUnauthorized.Error()
+	case http.StatusNotFound:
+		apiErr.sentinel = ErrNotFound
+		apiErr.Message = ErrNotFound.Error()
+	case http.StatusBadGateway:
+		apiErr.sentinel = ErrServiceNotReady
+		apiErr.Message = ErrServiceNotReady.Error()
+	case http.StatusForbidden:
+		apiErr.sentinel = ErrForbidden
+	case http.StatusConflict:
+		apiErr.sentinel = ErrConflict
 	}
-	var res *http.Response
-	client := &http.Client{}
-	res, err = client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("error in the request : %v", err)
+	if strings.TrimSpace(apiErr.Message) == "" && apiErr.sentinel != nil {
+		apiErr.Message = apiErr.sentinel.Error()
 	}
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(res.Body)
-	respBytes := buf.String()
 
-	respString := string(respBytes)
+	return apiErr
+}
 
-	var rrt ResponseRefreshToken
-	err = json.Unmarshal([]byte(respString), &rrt)
-	if err != nil {
-		return "", fmt.Errorf("error: cannot read the response json: %v", err)
+// parseErrorMessage returns the message of an error response body, which can be plain text or
+// a JSON object with a "message", "error" or "detail" field
+func parseErrorMessage(body []byte) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err == nil {
+		for _, key := range []string{"message", "error", "detail"} {
+			if message, ok := fields[key].(string); ok && message != "" {
+				return message
+			}
+		}
 	}
-	return rrt.AccessToken, nil
+	return string(body)
 }
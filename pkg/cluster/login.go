@@ -0,0 +1,212 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// devicePollIncrement is added to the polling interval when the provider asks to slow down
+	devicePollIncrement = 5 * time.Second
+	loginCallbackPath   = "/callback"
+)
+
+// defaultDevicePollInterval is used when the provider doesn't set the polling interval of the device flow
+var defaultDevicePollInterval = 5 * time.Second
+
+// DeviceAuthorization is the response of the device authorization endpoint
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceLogin gets the tokens of a user through the OAuth 2.0 device authorization grant. The prompt function
+// shows the user where to log in, and the provider is polled until the login is completed or ctx is done
+func DeviceLogin(ctx context.Context, client *http.Client, discovery *OIDCDiscovery, clientID, scope string, prompt func(*DeviceAuthorization)) (*ResponseRefreshToken, error) {
+	if discovery.DeviceAuthorizationEndpoint == "" {
+		return nil, errors.New("the OIDC provider doesn't support the device authorization grant")
+	}
+
+	auth := &DeviceAuthorization{}
+	form := url.Values{"client_id": {clientID}}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+	if err := postForm(ctx, client, discovery.DeviceAuthorizationEndpoint, form, auth); err != nil {
+		return nil, err
+	}
+	if auth.DeviceCode == "" {
+		return nil, errors.New("the OIDC provider didn't return a device code")
+	}
+	prompt(auth)
+
+	if auth.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(auth.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+	interval := defaultDevicePollInterval
+	if auth.Interval > 0 {
+		interval = time.Duration(auth.Interval) * time.Second
+	}
+
+	form = url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {auth.DeviceCode},
+		"client_id":   {clientID},
+	}
+	for {
+		response, err := RequestToken(ctx, client, discovery.TokenEndpoint, form)
+		var oidcErr *OIDCError
+		switch {
+		case err == nil:
+			return response, nil
+		case errors.As(err, &oidcErr) && oidcErr.Code == "authorization_pending":
+		case errors.As(err, &oidcErr) && oidcErr.Code == "slow_down":
+			interval += devicePollIncrement
+		case ctx.Err() != nil:
+			return nil, loginContextError(ctx)
+		default:
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, loginContextError(ctx)
+		case <-time.After(interval):
+		}
+	}
+}
+
+// BrowserLogin gets the tokens of a user through the OAuth 2.0 authorization code grant with PKCE, receiving
+// the code in a local callback server listening on the given port (a random one if 0). The open function sends
+// the user to the authorization URL
+func BrowserLogin(ctx context.Context, client *http.Client, discovery *OIDCDiscovery, clientID, scope string, port int, open func(authURL string) error) (*ResponseRefreshToken, error) {
+	if discovery.AuthorizationEndpoint == "" {
+		return nil, errors.New("the OIDC provider doesn't define an authorization endpoint")
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the login callback server: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), loginCallbackPath)
+
+	verifier := randomURLSafeString(32)
+	challenge := sha256.Sum256([]byte(verifier))
+	state := randomURLSafeString(16)
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", clientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	authURL.RawQuery = query.Encode()
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != loginCallbackPath {
+			http.NotFound(w, r)
+			return
+		}
+		params := r.URL.Query()
+		result := callbackResult{code: params.Get("code")}
+		switch {
+		case params.Get("state") != state:
+			result.err = errors.New("invalid state in the login callback")
+		case params.Get("error") != "":
+			result.err = &OIDCError{Code: params.Get("error"), Description: params.Get("error_description")}
+		case result.code == "":
+			result.err = errors.New("the login callback didn't receive an authorization code")
+		}
+		if result.err != nil {
+			http.Error(w, "Login failed: "+result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login completed, you can close this window.")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := open(authURL.String()); err != nil {
+		return nil, err
+	}
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		return nil, loginContextError(ctx)
+	case result = <-results:
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return RequestToken(ctx, client, discovery.TokenEndpoint, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {result.code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+	})
+}
+
+func loginContextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New("the login was not completed in time")
+	}
+	return ctx.Err()
+}
+
+func randomURLSafeString(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeviceLoginPolling(t *testing.T) {
+	originalInterval := defaultDevicePollInterval
+	defaultDevicePollInterval = time.Millisecond
+	t.Cleanup(func() { defaultDevicePollInterval = originalInterval })
+
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			json.NewEncoder(w).Encode(DeviceAuthorization{DeviceCode: "device", UserCode: "CODE", VerificationURI: "https://example.com"})
+		case "/token":
+			if r.PostFormValue("grant_type") != deviceCodeGrantType || r.PostFormValue("device_code") != "device" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant"}`)
+				return
+			}
+			if atomic.AddInt32(&polls, 1) < 3 {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"authorization_pending"}`)
+				return
+			}
+			fmt.Fprint(w, `{"access_token":"access","refresh_token":"refresh","expires_in":300}`)
+		}
+	}))
+	defer server.Close()
+
+	discovery := &OIDCDiscovery{TokenEndpoint: server.URL + "/token", DeviceAuthorizationEndpoint: server.URL + "/device"}
+	userCode := ""
+	token, err := DeviceLogin(context.Background(), server.Client(), discovery, "oscar-cli", "openid", func(auth *DeviceAuthorization) {
+		userCode = auth.UserCode
+	})
+	if err != nil {
+		t.Fatalf("device login returned error: %v", err)
+	}
+	if token.RefreshToken != "refresh" || userCode != "CODE" || atomic.LoadInt32(&polls) != 3 {
+		t.Fatalf("unexpected token %+v (user code %q, %d polls)", token, userCode, polls)
+	}
+}
+
+func TestDeviceLoginDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/device" {
+			fmt.Fprint(w, `{"device_code":"device","user_code":"CODE","verification_uri":"https://example.com"}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"access_denied","error_description":"The user denied the request"}`)
+	}))
+	defer server.Close()
+
+	discovery := &OIDCDiscovery{TokenEndpoint: server.URL + "/token", DeviceAuthorizationEndpoint: server.URL + "/device"}
+	_, err := DeviceLogin(context.Background(), server.Client(), discovery, "oscar-cli", "", func(*DeviceAuthorization) {})
+	var oidcErr *OIDCError
+	if !errors.As(err, &oidcErr) || oidcErr.Code != "access_denied" {
+		t.Fatalf("expected an access_denied error, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+	// defaultAccessTokenLifetime is used when the OIDC provider doesn't report the expiration of the access tokens
+	defaultAccessTokenLifetime = 5 * time.Minute
+	tokenCacheDirName          = "oscar-cli/tokens"
+)
+
+// refreshMu serializes the refresh of the OIDC tokens, as the refresh tokens can be rotated and
+// the access tokens are cached on disk
+var refreshMu sync.Mutex
+
+// OIDCDiscovery is the subset of the OpenID provider metadata used by oscar-cli
+type OIDCDiscovery struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+type oidcErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// OIDCError is an error response of an OpenID provider
+type OIDCError struct {
+	Code        string
+	Description string
+}
+
+func (e *OIDCError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("the OIDC provider returned the error \"%s\"", e.Code)
+	}
+	return fmt.Sprintf("the OIDC provider returned the error \"%s\": %s", e.Code, e.Description)
+}
+
+type cachedAccessToken struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// SetRefreshTokenHandler sets the function called with the new OIDC refresh token when the provider rotates it,
+// so it can be stored in the configuration
+func (cluster *Cluster) SetRefreshTokenHandler(handler func(refreshToken string) error) {
+	cluster.refreshTokenHandler = handler
+}
+
+// DiscoverOIDC returns the metadata of an OpenID provider from its discovery document
+func DiscoverOIDC(client *http.Client, issuer string) (*OIDCDiscovery, error) {
+	res, err := client.Get(strings.TrimSuffix(issuer, "/") + oidcDiscoveryPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the OpenID configuration of \"%s\": %w", issuer, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to get the OpenID configuration of \"%s\": %s", issuer, res.Status)
+	}
+	discovery := &OIDCDiscovery{}
+	if err := json.NewDecoder(res.Body).Decode(discovery); err != nil {
+		return nil, fmt.Errorf("invalid OpenID configuration of \"%s\": %w", issuer, err)
+	}
+	if discovery.TokenEndpoint == "" {
+		return nil, fmt.Errorf("the OpenID configuration of \"%s\" doesn't define a token endpoint", issuer)
+	}
+	return discovery, nil
+}
+
+// RequestToken sends a request to the token endpoint of an OpenID provider, returning an *OIDCError
+// if the provider rejects it
+func RequestToken(ctx context.Context, client *http.Client, tokenEndpoint string, form url.Values) (*ResponseRefreshToken, error) {
+	response := &ResponseRefreshToken{}
+	if err := postForm(ctx, client, tokenEndpoint, form, response); err != nil {
+		return nil, err
+	}
+	if response.AccessToken == "" {
+		return nil, errors.New("the token endpoint didn't return an access token")
+	}
+	return response, nil
+}
+
+// postForm sends a form to an endpoint of an OpenID provider and decodes its JSON response
+func postForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to send the request to the OIDC provider: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		oidcErr := oidcErrorResponse{}
+		json.NewDecoder(res.Body).Decode(&oidcErr)
+		if oidcErr.Error != "" {
+			return &OIDCError{Code: oidcErr.Error, Description: oidcErr.ErrorDescription}
+		}
+		return fmt.Errorf("the OIDC provider returned %s", res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return fmt.Errorf("invalid response from the OIDC provider: %w", err)
+	}
+	return nil
+}
+
+// getAccessToken returns an access token from the refresh token of the cluster, reusing the one cached
+// on disk while it's valid
+func (cluster *Cluster) getAccessToken() (string, time.Time, error) {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	if cached, ok := readCachedToken(cluster.OIDCRefreshToken); ok && time.Until(cached.Expiry) > tokenRefreshMargin {
+		return cached.AccessToken, cached.Expiry, nil
+	}
+
+	issuer, clientID, scope, err := cluster.oidcClientConfig()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	client := &http.Client{Timeout: _DEFAULT_TIMEOUT * time.Second}
+	discovery, err := DiscoverOIDC(client, issuer)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {cluster.OIDCRefreshToken},
+		"client_id":     {clientID},
+	}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+	response, err := RequestToken(context.Background(), client, discovery.TokenEndpoint, form)
+	var oidcErr *OIDCError
+	if errors.As(err, &oidcErr) && oidcErr.Code == "invalid_grant" {
+		return "", time.Time{}, fmt.Errorf("%w (%s)", ErrRefreshTokenExpired, oidcErr.Description)
+	} else if err != nil {
+		return "", time.Time{}, err
+	}
+
+	// Store the new refresh token if the provider rotates it, as the previous one is no longer valid
+	if response.RefreshToken != "" && response.RefreshToken != cluster.OIDCRefreshToken {
+		removeCachedToken(cluster.OIDCRefreshToken)
+		cluster.OIDCRefreshToken = response.RefreshToken
+		if cluster.refreshTokenHandler != nil {
+			if err := cluster.refreshTokenHandler(response.RefreshToken); err != nil {
+				return "", time.Time{}, fmt.Errorf("unable to store the new refresh token: %w", err)
+			}
+		}
+	}
+
+	expiry := time.Now().Add(defaultAccessTokenLifetime)
+	if response.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	// The cache only saves requests, so failing to write it is not an error
+	writeCachedToken(cluster.OIDCRefreshToken, cachedAccessToken{AccessToken: response.AccessToken, Expiry: expiry})
+
+	return response.AccessToken, expiry, nil
+}
+
+// oidcClientConfig returns the issuer, client ID and scope used to refresh the tokens, from the cluster
+// configuration or the claims of the refresh token (which are not verified, as they are only sent back to the issuer)
+func (cluster *Cluster) oidcClientConfig() (issuer, clientID, scope string, err error) {
+	issuer, clientID, scope = cluster.OIDCIssuer, cluster.OIDCClientID, cluster.OIDCScope
+
+	claims := jwt.MapClaims{}
+	// Opaque refresh tokens (not JWT) require the issuer and client ID in the configuration
+	if _, _, err := jwt.NewParser().ParseUnverified(cluster.OIDCRefreshToken, claims); err == nil {
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil && exp.Before(time.Now()) {
+			return "", "", "", ErrRefreshTokenExpired
+		}
+		if issuer == "" {
+			issuer, _ = claims.GetIssuer()
+		}
+		if clientID == "" {
+			clientID, _ = claims["azp"].(string)
+		}
+		if scope == "" {
+			scope, _ = claims["scope"].(string)
+		}
+	}
+
+	if issuer == "" || clientID == "" {
+		return "", "", "", errors.New("unable to get the OIDC issuer and client ID from the refresh token, please set \"oidc_issuer\" and \"oidc_client_id\" in the cluster configuration")
+	}
+	return issuer, clientID, scope, nil
+}
+
+// tokenCachePath returns the file of the access token cache for a refresh token, named after its hash
+func tokenCachePath(refreshToken string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(refreshToken))
+	return filepath.Join(cacheDir, tokenCacheDirName, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+func readCachedToken(refreshToken string) (cachedAccessToken, bool) {
+	cached := cachedAccessToken{}
+	cachePath, err := tokenCachePath(refreshToken)
+	if err != nil {
+		return cached, false
+	}
+	content, err := os.ReadFile(cachePath)
+	if err != nil {
+		return cached, false
+	}
+	if err := json.Unmarshal(content, &cached); err != nil || cached.AccessToken == "" {
+		return cached, false
+	}
+	return cached, true
+}
+
+func writeCachedToken(refreshToken string, cached cachedAccessToken) {
+	cachePath, err := tokenCachePath(refreshToken)
+	if err != nil {
+		return
+	}
+	content, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err != nil {
+		return
+	}
+	os.WriteFile(cachePath, content, 0600)
+}
+
+func removeCachedToken(refreshToken string) {
+	if cachePath, err := tokenCachePath(refreshToken); err == nil {
+		os.Remove(cachePath)
+	}
+}
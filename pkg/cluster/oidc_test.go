@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type fakeIssuer struct {
+	*httptest.Server
+	requests     int32
+	rotate       bool
+	refreshToken string
+}
+
+// newFakeIssuer starts an OpenID provider that only accepts its current refresh token
+func newFakeIssuer(t *testing.T, rotate bool) *fakeIssuer {
+	t.Helper()
+	issuer := &fakeIssuer{rotate: rotate}
+	issuer.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case oidcDiscoveryPath:
+			json.NewEncoder(w).Encode(OIDCDiscovery{Issuer: issuer.URL, TokenEndpoint: issuer.URL + "/token"})
+		case "/token":
+			n := atomic.AddInt32(&issuer.requests, 1)
+			if r.PostFormValue("refresh_token") != issuer.refreshToken || r.PostFormValue("client_id") != "oscar-cli" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":"invalid_grant","error_description":"Token is not active"}`)
+				return
+			}
+			response := ResponseRefreshToken{AccessToken: fmt.Sprintf("access-%d", n), ExpiresIn: 300}
+			if issuer.rotate {
+				issuer.refreshToken = newRefreshToken(t, issuer.URL, time.Hour)
+				response.RefreshToken = issuer.refreshToken
+			}
+			json.NewEncoder(w).Encode(response)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(issuer.Close)
+	issuer.refreshToken = newRefreshToken(t, issuer.URL, time.Hour)
+	return issuer
+}
+
+func newRefreshToken(t *testing.T, issuer string, validity time.Duration) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":   issuer,
+		"azp":   "oscar-cli",
+		"scope": "openid offline_access",
+		"exp":   time.Now().Add(validity).Unix(),
+		"jti":   fmt.Sprint(time.Now().UnixNano()),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("signing refresh token: %v", err)
+	}
+	return token
+}
+
+func TestGetAccessTokenCachesToken(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	issuer := newFakeIssuer(t, false)
+	c := &Cluster{OIDCRefreshToken: issuer.refreshToken}
+
+	token, expiry, err := c.getAccessToken()
+	if err != nil {
+		t.Fatalf("getting access token: %v", err)
+	}
+	if token != "access-1" || time.Until(expiry) < 4*time.Minute {
+		t.Fatalf("unexpected token %q expiring at %v", token, expiry)
+	}
+
+	// Another cluster with the same refresh token reuses the token cached on disk
+	token, _, err = (&Cluster{OIDCRefreshToken: issuer.refreshToken}).getAccessToken()
+	if err != nil || token != "access-1" {
+		t.Fatalf("expected the cached token, got %q, %v", token, err)
+	}
+	if n := atomic.LoadInt32(&issuer.requests); n != 1 {
+		t.Fatalf("expected a single token request, got %d", n)
+	}
+}
+
+func TestGetAccessTokenRotatesRefreshToken(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	issuer := newFakeIssuer(t, true)
+	original := issuer.refreshToken
+
+	stored := ""
+	c := &Cluster{OIDCRefreshToken: original}
+	c.SetRefreshTokenHandler(func(refreshToken string) error {
+		stored = refreshToken
+		return nil
+	})
+
+	if _, _, err := c.getAccessToken(); err != nil {
+		t.Fatalf("getting access token: %v", err)
+	}
+	if c.OIDCRefreshToken == original || stored != c.OIDCRefreshToken || stored != issuer.refreshToken {
+		t.Fatalf("expected the rotated refresh token to be stored")
+	}
+
+	// The previous refresh token is no longer valid
+	_, _, err := (&Cluster{OIDCRefreshToken: original}).getAccessToken()
+	if !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Fatalf("expected ErrRefreshTokenExpired, got %v", err)
+	}
+}
+
+func TestGetAccessTokenExpiredRefreshToken(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	issuer := newFakeIssuer(t, false)
+
+	_, err := (&Cluster{Endpoint: issuer.URL, OIDCRefreshToken: newRefreshToken(t, issuer.URL, -time.Hour)}).GetClientSafe()
+	if !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Fatalf("expected ErrRefreshTokenExpired, got %v", err)
+	}
+	if n := atomic.LoadInt32(&issuer.requests); n != 0 {
+		t.Fatalf("expected no token requests, got %d", n)
+	}
+}
+
+func TestGetAccessTokenOpaqueRefreshToken(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	issuer := newFakeIssuer(t, false)
+	issuer.refreshToken = "opaque-token"
+
+	if _, _, err := (&Cluster{OIDCRefreshToken: "opaque-token"}).getAccessToken(); err == nil {
+		t.Fatalf("expected an error without issuer and client ID")
+	}
+
+	c := &Cluster{OIDCRefreshToken: "opaque-token", OIDCIssuer: issuer.URL, OIDCClientID: "oscar-cli"}
+	if token, _, err := c.getAccessToken(); err != nil || token != "access-1" {
+		t.Fatalf("unexpected token %q, %v", token, err)
+	}
+}
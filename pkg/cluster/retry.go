@@ -0,0 +1,201 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+	// idempotencyKeyHeader marks POST requests that can be safely retried
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// RetryPolicy configures how the failed requests to a cluster are retried
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of each request, 0 disables the retries
+	MaxRetries int `json:"max_retries"`
+	// InitialBackoff is the delay before the first retry (e.g. "500ms"), doubled on each retry
+	InitialBackoff string `json:"initial_backoff,omitempty"`
+	// MaxBackoff is the maximum delay between retries (e.g. "10s")
+	MaxBackoff string `json:"max_backoff,omitempty"`
+}
+
+type noRetriesKey struct{}
+
+// WithoutRetries returns a context whose requests are never retried, e.g. to measure the cluster behaviour
+func WithoutRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetriesKey{}, true)
+}
+
+type retryRoundTripper struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	transport      http.RoundTripper
+}
+
+func (cluster *Cluster) newRetryRoundTripper(transport http.RoundTripper) (*retryRoundTripper, error) {
+	rrt := &retryRoundTripper{
+		maxRetries:     defaultMaxRetries,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+		transport:      transport,
+	}
+
+	if cluster.Retry != nil {
+		rrt.maxRetries = cluster.Retry.MaxRetries
+		if cluster.Retry.InitialBackoff != "" {
+			backoff, err := time.ParseDuration(cluster.Retry.InitialBackoff)
+			if err != nil || backoff <= 0 {
+				return nil, fmt.Errorf("invalid initial backoff \"%s\" in the retry policy", cluster.Retry.InitialBackoff)
+			}
+			rrt.initialBackoff = backoff
+		}
+		if cluster.Retry.MaxBackoff != "" {
+			backoff, err := time.ParseDuration(cluster.Retry.MaxBackoff)
+			if err != nil || backoff <= 0 {
+				return nil, fmt.Errorf("invalid max backoff \"%s\" in the retry policy", cluster.Retry.MaxBackoff)
+			}
+			rrt.maxBackoff = backoff
+		}
+	}
+	if rrt.maxRetries < 0 {
+		return nil, errors.New("the maximum number of retries cannot be negative")
+	}
+
+	return rrt, nil
+}
+
+// RoundTrip function to implement the RoundTripper interface retrying the transient failures with exponential
+// backoff and jitter. Only idempotent requests are retried, except POST requests that were not processed
+func (rrt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if rrt.maxRetries == 0 || req.Context().Value(noRetriesKey{}) != nil {
+		return rrt.transport.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := rrt.transport.RoundTrip(req)
+		if attempt >= rrt.maxRetries || !shouldRetry(req, res, err) {
+			return res, err
+		}
+
+		delay := rrt.backoff(attempt)
+		if res != nil {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			// Drain the body to reuse the connection
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		// Rewind the body for the next attempt
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the given retry, with jitter to avoid synchronized retries
+func (rrt *retryRoundTripper) backoff(attempt int) time.Duration {
+	delay := rrt.maxBackoff
+	if attempt < 32 && rrt.initialBackoff<<attempt < rrt.maxBackoff {
+		delay = rrt.initialBackoff << attempt
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// shouldRetry reports whether the request may succeed if retried
+func shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	// Requests whose body can't be sent again are never retried
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil && req.Context().Err() != nil {
+		return false
+	}
+
+	if isIdempotent(req) {
+		if err != nil {
+			return true
+		}
+		switch res.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	// Other requests are only retried if the cluster didn't process them
+	if err != nil {
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && opErr.Op == "dial"
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(idempotencyKeyHeader) != ""
+}
+
+// parseRetryAfter parses the value of a Retry-After header, in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryCluster(endpoint string, maxRetries int) *Cluster {
+	return &Cluster{
+		Endpoint: endpoint,
+		Retry:    &RetryPolicy{MaxRetries: maxRetries, InitialBackoff: "1ms", MaxBackoff: "5ms"},
+	}
+}
+
+func newStatusServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		status := http.StatusOK
+		if n <= len(statuses) {
+			status = statuses[n-1]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	server, requests := newStatusServer(t, http.StatusBadGateway, http.StatusTooManyRequests)
+	client, err := newRetryCluster(server.URL, 3).GetClientSafe()
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || atomic.LoadInt32(requests) != 3 {
+		t.Fatalf("expected a success after 3 requests, got status %d after %d requests", res.StatusCode, *requests)
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	server, requests := newStatusServer(t, 503, 503, 503, 503)
+	client, err := newRetryCluster(server.URL, 2).GetClientSafe()
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+
+	res, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(requests) != 3 {
+		t.Fatalf("expected the last failure after 3 requests, got status %d after %d requests", res.StatusCode, *requests)
+	}
+}
+
+func TestRetryPostRequests(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		header   string
+		expected int32
+	}{
+		{"bad gateway is not retried", http.StatusBadGateway, "", 1},
+		{"service unavailable is retried", http.StatusServiceUnavailable, "", 2},
+		{"idempotency key is retried", http.StatusBadGateway, "key", 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server, requests := newStatusServer(t, tc.status)
+			client, err := newRetryCluster(server.URL, 3).GetClientSafe()
+			if err != nil {
+				t.Fatalf("creating client: %v", err)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString("payload"))
+			if tc.header != "" {
+				req.Header.Set("Idempotency-Key", tc.header)
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res.Body.Close()
+			if got := atomic.LoadInt32(requests); got != tc.expected {
+				t.Fatalf("expected %d requests, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	server, requests := newStatusServer(t, 503)
+
+	client, err := newRetryCluster(server.URL, 3).GetClientSafe()
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	req, _ := http.NewRequestWithContext(WithoutRetries(context.Background()), http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if atomic.LoadInt32(requests) != 1 {
+		t.Fatalf("expected the request not to be retried, got %d requests", *requests)
+	}
+
+	client, err = newRetryCluster(server.URL, 0).GetClientSafe()
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	res, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res.Body.Close()
+	if atomic.LoadInt32(requests) != 2 {
+		t.Fatalf("expected the policy to disable the retries, got %d requests", *requests)
+	}
+}
+
+func TestRetryInvalidPolicy(t *testing.T) {
+	c := &Cluster{Endpoint: "http://localhost", Retry: &RetryPolicy{MaxRetries: 1, InitialBackoff: "soon"}}
+	if _, err := c.GetClientSafe(); err == nil {
+		t.Fatal("expected an error for the invalid backoff")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if delay, ok := parseRetryAfter("3"); !ok || delay != 3*time.Second {
+		t.Fatalf("unexpected delay %v, %v", delay, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if delay, ok := parseRetryAfter(date); !ok || delay <= 0 || delay > time.Hour {
+		t.Fatalf("unexpected delay %v, %v", delay, ok)
+	}
+	if _, ok := parseRetryAfter("later"); ok {
+		t.Fatal("expected an invalid Retry-After to be ignored")
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is the validity left when a cached token is renewed
+const tokenRefreshMargin = time.Minute
+
+// tokenFetcher returns a new access token and its expiration time
+type tokenFetcher func() (token string, expiry time.Time, err error)
+
+// cachedTokenSource returns the same access token until it's about to expire, so long-lived clients
+// keep working without fetching a token per request
+type cachedTokenSource struct {
+	mu     sync.Mutex
+	fetch  tokenFetcher
+	token  string
+	expiry time.Time
+}
+
+func newCachedTokenSource(fetch tokenFetcher) *cachedTokenSource {
+	return &cachedTokenSource{fetch: fetch}
+}
+
+// Token returns a valid access token, fetching a new one if needed
+func (s *cachedTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiry) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	token, expiry, err := s.fetch()
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	s.expiry = expiry
+	return token, nil
+}
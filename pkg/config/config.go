@@ -99,9 +99,33 @@ func ReadConfig(configPath string) (config *Config, err error) {
 	}
 	config.normalizeClusterOrder()
 
+	// Store the OIDC refresh tokens rotated by the providers, as the previous ones are no longer valid
+	for id, c := range config.Oscar {
+		if c != nil && c.OIDCRefreshToken != "" {
+			c.SetRefreshTokenHandler(func(refreshToken string) error {
+				return SetRefreshToken(configPath, id, refreshToken)
+			})
+		}
+	}
+
 	return config, nil
 }
 
+// SetRefreshToken updates the OIDC refresh token of a cluster in the configuration file
+func SetRefreshToken(configPath, id, refreshToken string) error {
+	// Read the file again to keep the changes made since it was loaded
+	config, err := ReadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if err := config.CheckCluster(id); err != nil {
+		return err
+	}
+	config.Oscar[id].OIDCRefreshToken = refreshToken
+
+	return config.writeConfig(configPath)
+}
+
 func (config *Config) writeConfig(configPath string) (err error) {
 	// Marshal the config content (YAML or JSON)
 	configExtension := filepath.Ext(configPath)
@@ -133,16 +157,28 @@ func (config *Config) writeConfig(configPath string) (err error) {
 // AddCluster adds a new cluster to the config
 func (config *Config) AddCluster(configPath string, id string, endpoint string, authUser string, authPassword string, oidcAccountName string, oidcRefreshToken string, sslVerify bool) error {
 	// Add (or overwrite) the new cluster
-	config.Oscar[id] = &cluster.Cluster{
+	return config.SetCluster(configPath, id, &cluster.Cluster{
 		Endpoint:         endpoint,
 		AuthUser:         authUser,
 		AuthPassword:     authPassword,
 		OIDCAccountName:  oidcAccountName,
 		OIDCRefreshToken: oidcRefreshToken,
 		SSLVerify:        sslVerify,
-		Memory:           defaultMemory,
-		LogLevel:         defaultLogLevel,
+	})
+}
+
+// SetCluster adds or replaces a cluster in the config, setting the default memory and log level if empty
+func (config *Config) SetCluster(configPath string, id string, c *cluster.Cluster) error {
+	if config.Oscar == nil {
+		config.Oscar = map[string]*cluster.Cluster{}
+	}
+	if c.Memory == "" {
+		c.Memory = defaultMemory
+	}
+	if c.LogLevel == "" {
+		c.LogLevel = defaultLogLevel
 	}
+	config.Oscar[id] = c
 
 	// If there is only one cluster set as default
 	if len(config.Oscar) == 1 {
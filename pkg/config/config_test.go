@@ -144,3 +144,44 @@ func TestGetClusterResolution(t *testing.T) {
 		}
 	})
 }
+
+func TestSetRefreshToken(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	content := `oscar:
+  alpha:
+    endpoint: "https://alpha"
+    oidc_refresh_token: "old-token"
+    ssl_verify: true
+    memory: 256Mi
+    log_level: INFO
+  beta:
+    endpoint: "https://beta"
+    oidc_refresh_token: "other-token"
+    ssl_verify: true
+    memory: 256Mi
+    log_level: INFO
+default: alpha
+`
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	if err := SetRefreshToken(configPath, "alpha", "new-token"); err != nil {
+		t.Fatalf("SetRefreshToken returned error: %v", err)
+	}
+
+	conf, err := ReadConfig(configPath)
+	if err != nil {
+		t.Fatalf("ReadConfig returned error: %v", err)
+	}
+	if got := conf.Oscar["alpha"].OIDCRefreshToken; got != "new-token" {
+		t.Fatalf("expected the new refresh token, got %q", got)
+	}
+	if got := conf.Oscar["beta"].OIDCRefreshToken; got != "other-token" {
+		t.Fatalf("unexpected refresh token of beta %q", got)
+	}
+	if err := SetRefreshToken(configPath, "missing", "token"); err == nil {
+		t.Fatalf("expected an error for a missing cluster")
+	}
+}
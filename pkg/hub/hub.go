@@ -47,6 +47,7 @@ type Client struct {
 	baseAPI    string
 	httpClient *http.Client
 	logWriter  io.Writer
+	fdlValues  service.FDLValues
 }
 
 // Option mutates the client configuration.
@@ -117,6 +118,13 @@ func WithLogWriter(w io.Writer) Option {
 	}
 }
 
+// WithFDLValues sets the values used to resolve the placeholders of the fetched FDL files.
+func WithFDLValues(values service.FDLValues) Option {
+	return func(c *Client) {
+		c.fdlValues = values
+	}
+}
+
 // NewClient builds a client with sensible defaults.
 func NewClient(opts ...Option) *Client {
 	client := &Client{
@@ -505,6 +513,13 @@ func (c *Client) FetchFDL(ctx context.Context, slug string) (*service.FDL, error
 		return nil, err
 	}
 
+	if c.fdlValues != nil {
+		rawFDL, err = service.RenderFDL(rawFDL, c.fdlValues)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var parsed service.FDL
 	if err := yaml.Unmarshal(rawFDL, &parsed); err != nil {
 		return nil, fmt.Errorf("parsing FDL: %w", err)
@@ -568,6 +583,11 @@ func (c *Client) embedArtifacts(ctx context.Context, repoPath string, fdl *servi
 
 // LoadLocalFDL loads an FDL definition from a local directory or file.
 func LoadLocalFDL(localRoot, slug string) (*service.FDL, error) {
+	return LoadLocalFDLWithValues(localRoot, slug, nil)
+}
+
+// LoadLocalFDLWithValues loads a local FDL definition resolving its placeholders with the provided values.
+func LoadLocalFDLWithValues(localRoot, slug string, values service.FDLValues) (*service.FDL, error) {
 	localRoot = filepath.Clean(localRoot)
 	info, err := os.Stat(localRoot)
 	if err != nil {
@@ -577,7 +597,7 @@ func LoadLocalFDL(localRoot, slug string) (*service.FDL, error) {
 	if !info.IsDir() {
 		ext := strings.ToLower(filepath.Ext(localRoot))
 		if ext == ".yaml" || ext == ".yml" {
-			return readFDLFromFile(localRoot)
+			return readFDLFromFile(localRoot, values)
 		}
 		return nil, fmt.Errorf("unsupported file %s: expected an FDL (.yaml/.yml)", localRoot)
 	}
@@ -600,7 +620,7 @@ func LoadLocalFDL(localRoot, slug string) (*service.FDL, error) {
 			lastErr = err
 			continue
 		}
-		return readFDLFromFile(fdlPath)
+		return readFDLFromFile(fdlPath, values)
 	}
 
 	if lastErr != nil {
@@ -632,12 +652,19 @@ func selectLocalFDLFile(dir, slug string, entries []fs.DirEntry) (string, error)
 	return "", fmt.Errorf("fdl file not found in %s", dir)
 }
 
-func readFDLFromFile(path string) (*service.FDL, error) {
+func readFDLFromFile(path string, values service.FDLValues) (*service.FDL, error) {
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("cannot read FDL %s: %w", path, err)
 	}
 
+	if values != nil {
+		content, err = service.RenderFDL(content, values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	fdl := &service.FDL{}
 	if err := yaml.Unmarshal(content, fdl); err != nil {
 		return nil, fmt.Errorf("the FDL file %s is not valid, please check its definition", path)
@@ -0,0 +1,172 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oscartest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// Issuer is an OpenID provider for tests that approves every login right away. It supports the discovery
+// document, the device authorization grant, the authorization code grant with PKCE and the rotation of
+// refresh tokens
+type Issuer struct {
+	// URL of the issuer
+	URL string
+	// ClientID accepted by the issuer
+	ClientID string
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	codes         map[string]authorization
+	refreshTokens map[string]bool
+	tokenCount    int
+}
+
+// authorization is a pending authorization code and its PKCE challenge
+type authorization struct {
+	redirectURI string
+	challenge   string
+}
+
+// NewIssuer starts an OpenID provider for the given client that is closed when the test finishes
+func NewIssuer(t testing.TB, clientID string) *Issuer {
+	t.Helper()
+
+	i := &Issuer{
+		ClientID:      clientID,
+		codes:         map[string]authorization{},
+		refreshTokens: map[string]bool{},
+	}
+	i.server = httptest.NewServer(http.HandlerFunc(i.serve))
+	i.URL = i.server.URL
+	t.Cleanup(i.server.Close)
+
+	return i
+}
+
+// RefreshTokenValid checks if a refresh token was issued and has not been rotated yet
+func (i *Issuer) RefreshTokenValid(refreshToken string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.refreshTokens[refreshToken]
+}
+
+func (i *Issuer) serve(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                        i.URL,
+			"authorization_endpoint":        i.URL + "/authorize",
+			"token_endpoint":                i.URL + "/token",
+			"device_authorization_endpoint": i.URL + "/device",
+		})
+	case "/authorize":
+		query := r.URL.Query()
+		if query.Get("client_id") != i.ClientID || query.Get("code_challenge_method") != "S256" {
+			http.Error(w, "invalid authorization request", http.StatusBadRequest)
+			return
+		}
+		redirectURI, err := url.Parse(query.Get("redirect_uri"))
+		if err != nil {
+			http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+			return
+		}
+		code := randomToken()
+		i.codes[code] = authorization{redirectURI: query.Get("redirect_uri"), challenge: query.Get("code_challenge")}
+		params := redirectURI.Query()
+		params.Set("code", code)
+		params.Set("state", query.Get("state"))
+		redirectURI.RawQuery = params.Encode()
+		http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+	case "/device":
+		if r.PostFormValue("client_id") != i.ClientID {
+			writeOIDCError(w, "invalid_client")
+			return
+		}
+		code := randomToken()
+		i.codes[code] = authorization{}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"device_code":               code,
+			"user_code":                 "OSCAR-TEST",
+			"verification_uri":          i.URL + "/activate",
+			"verification_uri_complete": i.URL + "/activate?user_code=OSCAR-TEST",
+			"expires_in":                600,
+		})
+	case "/token":
+		i.handleToken(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (i *Issuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") != i.ClientID {
+		writeOIDCError(w, "invalid_client")
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		if _, ok := i.codes[r.PostFormValue("device_code")]; !ok {
+			writeOIDCError(w, "expired_token")
+			return
+		}
+		delete(i.codes, r.PostFormValue("device_code"))
+	case "authorization_code":
+		auth, ok := i.codes[r.PostFormValue("code")]
+		delete(i.codes, r.PostFormValue("code"))
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !ok || auth.redirectURI != r.PostFormValue("redirect_uri") || auth.challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			writeOIDCError(w, "invalid_grant")
+			return
+		}
+	case "refresh_token":
+		if !i.refreshTokens[r.PostFormValue("refresh_token")] {
+			writeOIDCError(w, "invalid_grant")
+			return
+		}
+		delete(i.refreshTokens, r.PostFormValue("refresh_token"))
+	default:
+		writeOIDCError(w, "unsupported_grant_type")
+		return
+	}
+
+	i.tokenCount++
+	refreshToken := fmt.Sprintf("refresh-%d-%s", i.tokenCount, randomToken())
+	i.refreshTokens[refreshToken] = true
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  fmt.Sprintf("access-%d", i.tokenCount),
+		"token_type":    "Bearer",
+		"expires_in":    300,
+		"refresh_token": refreshToken,
+	})
+}
+
+func writeOIDCError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
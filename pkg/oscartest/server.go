@@ -0,0 +1,452 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oscartest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar/v3/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Version is the version reported by the fake cluster
+	Version = "oscartest"
+	// S3 credentials and region of the MinIO provider of the fake cluster
+	S3AccessKey = "oscartest"
+	S3SecretKey = "oscartest-secret"
+	S3Region    = "us-east-1"
+)
+
+// RunHandler returns the status code and body of the response of a synchronous invocation
+type RunHandler func(svc *types.Service, input []byte) (int, []byte)
+
+// EchoRunHandler responds to the invocations with their input
+func EchoRunHandler(_ *types.Service, input []byte) (int, []byte) {
+	return http.StatusOK, input
+}
+
+// Server is an in-memory OSCAR cluster implementing the OSCAR API (services, logs, buckets, config,
+// info and the synchronous and asynchronous invocations) and an S3-compatible server for its MinIO buckets
+type Server struct {
+	// URL of the OSCAR API
+	URL string
+	// S3URL is the endpoint of the MinIO provider of the cluster
+	S3URL string
+
+	username   string
+	password   string
+	runHandler RunHandler
+	api        *httptest.Server
+	s3         *httptest.Server
+
+	mu       sync.Mutex
+	services map[string]*types.Service
+	jobs     map[string]map[string]*job
+	buckets  map[string]*bucket
+	requests []string
+	jobCount int
+}
+
+type job struct {
+	info *types.JobInfo
+	logs string
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithCredentials requires basic auth with the given credentials in the requests to the OSCAR API
+func WithCredentials(username, password string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithRunHandler sets how the synchronous invocations are handled, EchoRunHandler by default
+func WithRunHandler(handler RunHandler) Option {
+	return func(s *Server) {
+		s.runHandler = handler
+	}
+}
+
+// NewServer starts a fake OSCAR cluster that is closed when the test finishes
+func NewServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s := &Server{
+		runHandler: EchoRunHandler,
+		services:   map[string]*types.Service{},
+		jobs:       map[string]map[string]*job{},
+		buckets:    map[string]*bucket{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.api = httptest.NewServer(http.HandlerFunc(s.serveAPI))
+	s.s3 = httptest.NewServer(http.HandlerFunc(s.serveS3))
+	s.URL = s.api.URL
+	s.S3URL = s.s3.URL
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Close shuts down the servers
+func (s *Server) Close() {
+	s.api.Close()
+	s.s3.Close()
+}
+
+// Cluster returns the configuration of a cluster pointing to the server
+func (s *Server) Cluster() *cluster.Cluster {
+	return &cluster.Cluster{
+		Endpoint:     s.URL,
+		AuthUser:     s.username,
+		AuthPassword: s.password,
+		SSLVerify:    false,
+		Memory:       "256Mi",
+		LogLevel:     "INFO",
+	}
+}
+
+// Requests returns the requests received by the OSCAR API, as "METHOD PATH"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// AddService creates or replaces a service, creating the buckets of its MinIO input and output paths
+func (s *Server) AddService(svc *types.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putService(svc)
+}
+
+// Service returns a copy of a service
+func (s *Server) Service(name string) (*types.Service, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	svc, ok := s.services[name]
+	if !ok {
+		return nil, false
+	}
+	copied := *svc
+	return &copied, true
+}
+
+// AddJob creates or replaces a job of a service with the given logs
+func (s *Server) AddJob(svcName, jobName string, info *types.JobInfo, logs string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.jobs[svcName] == nil {
+		s.jobs[svcName] = map[string]*job{}
+	}
+	s.jobs[svcName][jobName] = &job{info: info, logs: logs}
+}
+
+// Jobs returns the jobs of a service
+func (s *Server) Jobs(svcName string) map[string]*types.JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := map[string]*types.JobInfo{}
+	for name, j := range s.jobs[svcName] {
+		jobs[name] = j.info
+	}
+	return jobs
+}
+
+func (s *Server) putService(svc *types.Service) {
+	if svc.Token == "" {
+		svc.Token = randomToken()
+	}
+	s.services[svc.Name] = svc
+	for _, storage := range append(append([]types.StorageIOConfig{}, svc.Input...), svc.Output...) {
+		if storage.Provider != "minio" && storage.Provider != "minio.default" {
+			continue
+		}
+		bucketName := strings.SplitN(strings.Trim(storage.Path, " /"), "/", 2)[0]
+		if bucketName != "" && s.buckets[bucketName] == nil {
+			s.buckets[bucketName] = newBucket(bucketName, s.owner())
+		}
+	}
+}
+
+func (s *Server) owner() string {
+	if s.username != "" {
+		return s.username
+	}
+	return "oscar"
+}
+
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case segments[0] == "run" && len(segments) == 2:
+		s.handleRun(w, r, segments[1])
+		return
+	case segments[0] == "job" && len(segments) == 2:
+		s.handleJob(w, r, segments[1])
+		return
+	case segments[0] != "system" || len(segments) < 2:
+		http.NotFound(w, r)
+		return
+	}
+
+	if !s.authorized(r, "") {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	switch segments[1] {
+	case "info":
+		writeJSON(w, http.StatusOK, types.Info{Version: Version})
+	case "config":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name":      "oscar",
+			"namespace": "oscar-svc",
+			"minio_provider": map[string]interface{}{
+				"endpoint":   s.S3URL,
+				"region":     S3Region,
+				"access_key": S3AccessKey,
+				"secret_key": S3SecretKey,
+				"verify":     false,
+			},
+		})
+	case "services":
+		s.handleServices(w, r, segments[2:])
+	case "logs":
+		s.handleLogs(w, r, segments[2:])
+	case "buckets":
+		s.handleBuckets(w, r, segments[2:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorized checks the basic auth credentials or, if given, the token of the service
+func (s *Server) authorized(r *http.Request, serviceToken string) bool {
+	if serviceToken != "" && r.Header.Get("Authorization") == "Bearer "+serviceToken {
+		return true
+	}
+	if s.username == "" {
+		return true
+	}
+	username, password, ok := r.BasicAuth()
+	return ok && username == s.username && password == s.password
+}
+
+func (s *Server) handleServices(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			names := make([]string, 0, len(s.services))
+			for name := range s.services {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			services := make([]*types.Service, 0, len(names))
+			for _, name := range names {
+				services = append(services, s.services[name])
+			}
+			writeJSON(w, http.StatusOK, services)
+		case http.MethodPost, http.MethodPut:
+			svc := &types.Service{}
+			if err := json.NewDecoder(r.Body).Decode(svc); err != nil || svc.Name == "" {
+				http.Error(w, "invalid service definition", http.StatusBadRequest)
+				return
+			}
+			existing, exists := s.services[svc.Name]
+			if r.Method == http.MethodPost && exists {
+				http.Error(w, fmt.Sprintf("service %s already exists", svc.Name), http.StatusConflict)
+				return
+			}
+			if r.Method == http.MethodPut && !exists {
+				http.NotFound(w, r)
+				return
+			}
+			if exists && svc.Token == "" {
+				svc.Token = existing.Token
+			}
+			s.putService(svc)
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			} else {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	svc, ok := s.services[segments[0]]
+	if !ok || len(segments) > 1 {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, svc)
+	case http.MethodDelete:
+		delete(s.services, svc.Name)
+		delete(s.jobs, svc.Name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 || len(segments) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	if _, ok := s.services[segments[0]]; !ok {
+		http.NotFound(w, r)
+		return
+	}
+	jobs := s.jobs[segments[0]]
+
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			infos := map[string]*types.JobInfo{}
+			for name, j := range jobs {
+				infos[name] = j.info
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": infos})
+		case http.MethodDelete:
+			all := r.URL.Query().Get("all") == "true"
+			for name, j := range jobs {
+				if all || j.info.Status == "Succeeded" || j.info.Status == "Failed" {
+					delete(jobs, name)
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	j, ok := jobs[segments[1]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		io.WriteString(w, j.logs)
+	case http.MethodDelete:
+		delete(jobs, segments[1])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request, name string) {
+	svc, ok := s.services[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r, svc.Token) {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	input, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	status, output := s.runHandler(svc, input)
+	w.WriteHeader(status)
+	w.Write(output)
+}
+
+// handleJob creates a job that finishes successfully right away, with the input as logs
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request, name string) {
+	svc, ok := s.services[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r, svc.Token) {
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	input, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.jobCount++
+	jobName := fmt.Sprintf("%s-job-%d", name, s.jobCount)
+	now := metav1.NewTime(time.Now())
+	if s.jobs[name] == nil {
+		s.jobs[name] = map[string]*job{}
+	}
+	s.jobs[name][jobName] = &job{
+		info: &types.JobInfo{Status: "Succeeded", CreationTime: &now, StartTime: &now, FinishTime: &now},
+		logs: string(input),
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"jobName": jobName})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
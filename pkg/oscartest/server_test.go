@@ -0,0 +1,150 @@
+package oscartest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/client"
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar-cli/pkg/service"
+	"github.com/grycap/oscar-cli/pkg/storage"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func newTestClient(t *testing.T, s *Server) *client.Client {
+	t.Helper()
+	c, err := client.New(s.Cluster())
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	return c
+}
+
+func TestServerServicesAndJobs(t *testing.T) {
+	s := NewServer(t, WithCredentials("user", "pass"))
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	svc := &types.Service{
+		Name:   "demo",
+		Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+		Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/out"}},
+	}
+	if err := c.CreateService(ctx, svc); err != nil {
+		t.Fatalf("creating service: %v", err)
+	}
+	if err := c.CreateService(ctx, svc); !errors.Is(err, cluster.ErrConflict) {
+		t.Fatalf("expected a conflict creating the service twice, got %v", err)
+	}
+	got, err := c.GetService(ctx, "demo")
+	if err != nil || got.Token == "" {
+		t.Fatalf("unexpected service %+v, %v", got, err)
+	}
+	if _, err := c.GetService(ctx, "missing"); !errors.Is(err, cluster.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	body, err := c.RunService(ctx, "demo", strings.NewReader("hello"), service.RunOptions{})
+	if err != nil {
+		t.Fatalf("running service: %v", err)
+	}
+	output, _ := io.ReadAll(body)
+	body.Close()
+	if string(output) != "hello" {
+		t.Fatalf("unexpected output %q", output)
+	}
+
+	body, err = c.JobService(ctx, "demo", strings.NewReader("async input"))
+	if err != nil {
+		t.Fatalf("invoking service: %v", err)
+	}
+	body.Close()
+	jobs, err := c.ListJobs(ctx, "demo")
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("unexpected jobs %v, %v", jobs, err)
+	}
+	logs, err := c.GetJobLogs(ctx, "demo", "demo-job-1", false)
+	if err != nil || logs != "async input" {
+		t.Fatalf("unexpected logs %q, %v", logs, err)
+	}
+	if err := c.DeleteJobs(ctx, "demo", false); err != nil {
+		t.Fatalf("deleting jobs: %v", err)
+	}
+	if len(s.Jobs("demo")) != 0 {
+		t.Fatalf("expected the finished jobs to be removed")
+	}
+
+	if err := c.DeleteService(ctx, "demo"); err != nil {
+		t.Fatalf("deleting service: %v", err)
+	}
+	if _, ok := s.Service("demo"); ok {
+		t.Fatalf("expected the service to be removed")
+	}
+}
+
+func TestServerRequiresCredentials(t *testing.T) {
+	s := NewServer(t, WithCredentials("user", "pass"))
+	conf := s.Cluster()
+	conf.AuthPassword = "wrong"
+	c, err := client.New(conf)
+	if err != nil {
+		t.Fatalf("creating client: %v", err)
+	}
+	if _, err := c.ListServices(context.Background()); !errors.Is(err, cluster.ErrUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func TestServerStorage(t *testing.T) {
+	s := NewServer(t)
+	c := newTestClient(t, s)
+	ctx := context.Background()
+
+	svc := &types.Service{
+		Name:  "demo",
+		Input: []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+	}
+	s.AddService(svc)
+
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(localPath, []byte("file content"), 0o600); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	if err := c.PutFile(ctx, svc, "minio.default", localPath, "demo/in/input.txt"); err != nil {
+		t.Fatalf("uploading file: %v", err)
+	}
+	if data, ok := s.Object("demo", "in/input.txt"); !ok || string(data) != "file content" {
+		t.Fatalf("unexpected object %q (found %v)", data, ok)
+	}
+
+	s.PutObject("demo", "out/result.txt", []byte("result"))
+	result, err := c.ListBucketObjects(ctx, "demo", &storage.BucketListOptions{})
+	if err != nil || len(result.Objects) != 2 {
+		t.Fatalf("unexpected objects %+v, %v", result, err)
+	}
+
+	downloadPath := filepath.Join(dir, "result.txt")
+	if err := c.GetFile(ctx, svc, "minio.default", "demo/out/result.txt", downloadPath); err != nil {
+		t.Fatalf("downloading file: %v", err)
+	}
+	if data, err := os.ReadFile(downloadPath); err != nil || string(data) != "result" {
+		t.Fatalf("unexpected downloaded content %q, %v", data, err)
+	}
+
+	buckets, err := c.ListBuckets(ctx)
+	if err != nil || len(buckets) != 1 || buckets[0].Name != "demo" {
+		t.Fatalf("unexpected buckets %v, %v", buckets, err)
+	}
+	if err := c.DeleteBucket(ctx, "demo"); err != nil {
+		t.Fatalf("deleting bucket: %v", err)
+	}
+	if s.Objects("demo") != nil {
+		t.Fatalf("expected the bucket to be removed")
+	}
+}
@@ -0,0 +1,461 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oscartest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type bucket struct {
+	name         string
+	visibility   string
+	allowedUsers []string
+	owner        string
+	objects      map[string]*object
+	uploads      map[string]map[int][]byte
+}
+
+type object struct {
+	data     []byte
+	modified time.Time
+}
+
+func newBucket(name, owner string) *bucket {
+	return &bucket{
+		name:       name,
+		visibility: "private",
+		owner:      owner,
+		objects:    map[string]*object{},
+		uploads:    map[string]map[int][]byte{},
+	}
+}
+
+func (o *object) etag() string {
+	sum := md5.Sum(o.data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// CreateBucket creates an empty bucket in the MinIO provider if it doesn't exist
+func (s *Server) CreateBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buckets[name] == nil {
+		s.buckets[name] = newBucket(name, s.owner())
+	}
+}
+
+// PutObject stores an object in the MinIO provider, creating its bucket if needed
+func (s *Server) PutObject(bucketName, key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.buckets[bucketName] == nil {
+		s.buckets[bucketName] = newBucket(bucketName, s.owner())
+	}
+	s.buckets[bucketName].objects[key] = &object{data: append([]byte(nil), data...), modified: time.Now()}
+}
+
+// Object returns the content of an object of the MinIO provider
+func (s *Server) Object(bucketName, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.buckets[bucketName]
+	if b == nil || b.objects[key] == nil {
+		return nil, false
+	}
+	return append([]byte(nil), b.objects[key].data...), true
+}
+
+// Objects returns the sorted keys of the objects of a bucket
+func (s *Server) Objects(bucketName string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.buckets[bucketName]
+	if b == nil {
+		return nil
+	}
+	return b.sortedKeys()
+}
+
+func (b *bucket) sortedKeys() []string {
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type bucketPayload struct {
+	Name         string   `json:"bucket_name"`
+	Visibility   string   `json:"visibility"`
+	AllowedUsers []string `json:"allowed_users"`
+	Owner        string   `json:"owner,omitempty"`
+	Provider     string   `json:"provider,omitempty"`
+}
+
+type objectPayload struct {
+	ObjectName   string `json:"object_name"`
+	SizeBytes    int64  `json:"size_bytes"`
+	LastModified string `json:"last_modified"`
+	Owner        string `json:"owner"`
+}
+
+func (s *Server) handleBuckets(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			names := make([]string, 0, len(s.buckets))
+			for name := range s.buckets {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			buckets := make([]bucketPayload, 0, len(names))
+			for _, name := range names {
+				b := s.buckets[name]
+				buckets = append(buckets, bucketPayload{Name: b.name, Visibility: b.visibility, AllowedUsers: b.allowedUsers, Owner: b.owner, Provider: "minio.default"})
+			}
+			writeJSON(w, http.StatusOK, buckets)
+		case http.MethodPost, http.MethodPut:
+			payload := bucketPayload{}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || strings.TrimSpace(payload.Name) == "" {
+				http.Error(w, "invalid bucket definition", http.StatusBadRequest)
+				return
+			}
+			b, exists := s.buckets[payload.Name]
+			if r.Method == http.MethodPost && exists {
+				http.Error(w, fmt.Sprintf("bucket %s already exists", payload.Name), http.StatusConflict)
+				return
+			}
+			if r.Method == http.MethodPut && !exists {
+				http.NotFound(w, r)
+				return
+			}
+			if !exists {
+				b = newBucket(payload.Name, s.owner())
+				s.buckets[payload.Name] = b
+			}
+			if payload.Visibility != "" {
+				b.visibility = payload.Visibility
+			}
+			b.allowedUsers = payload.AllowedUsers
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+			} else {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	b, ok := s.buckets[segments[0]]
+	if !ok || len(segments) > 1 {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.listBucketObjects(w, r, b)
+	case http.MethodDelete:
+		delete(s.buckets, b.name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// listBucketObjects lists the objects of a bucket, paginated with the "limit" and "page" parameters
+func (s *Server) listBucketObjects(w http.ResponseWriter, r *http.Request, b *bucket) {
+	keys := b.sortedKeys()
+	if page := r.URL.Query().Get("page"); page != "" {
+		start := sort.SearchStrings(keys, page)
+		keys = keys[start:]
+	}
+
+	nextPage := ""
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && len(keys) > limit {
+		nextPage = keys[limit]
+		keys = keys[:limit]
+	}
+
+	objects := make([]objectPayload, 0, len(keys))
+	for _, key := range keys {
+		o := b.objects[key]
+		objects = append(objects, objectPayload{
+			ObjectName:   key,
+			SizeBytes:    int64(len(o.data)),
+			LastModified: o.modified.UTC().Format(time.RFC3339),
+			Owner:        b.owner,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"objects":        objects,
+		"next_page":      nextPage,
+		"is_truncated":   nextPage != "",
+		"returned_items": len(objects),
+	})
+}
+
+type s3Error struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+type s3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type s3Prefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type s3ListResult struct {
+	XMLName               xml.Name   `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string     `xml:"Name"`
+	Prefix                string     `xml:"Prefix"`
+	Delimiter             string     `xml:"Delimiter,omitempty"`
+	Marker                string     `xml:"Marker,omitempty"`
+	NextMarker            string     `xml:"NextMarker,omitempty"`
+	ContinuationToken     string     `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string     `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int        `xml:"KeyCount,omitempty"`
+	MaxKeys               int        `xml:"MaxKeys"`
+	IsTruncated           bool       `xml:"IsTruncated"`
+	Contents              []s3Object `xml:"Contents"`
+	CommonPrefixes        []s3Prefix `xml:"CommonPrefixes"`
+}
+
+type s3InitiateUploadResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type s3CompleteUploadResult struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+	Bucket  string   `xml:"Bucket"`
+	Key     string   `xml:"Key"`
+	ETag    string   `xml:"ETag"`
+}
+
+func writeXML(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(value)
+}
+
+func writeS3Error(w http.ResponseWriter, r *http.Request, status int, code string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	writeXML(w, status, s3Error{Code: code, Message: http.StatusText(status)})
+}
+
+// serveS3 implements the subset of the S3 API (path-style) used by the MinIO provider: bucket creation and listing,
+// and object upload (including multipart uploads), download, head and deletion
+func (s *Server) serveS3(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucketName, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucketName == "" {
+		writeS3Error(w, r, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+	b := s.buckets[bucketName]
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodPut:
+			if b == nil {
+				s.buckets[bucketName] = newBucket(bucketName, s.owner())
+			}
+			w.WriteHeader(http.StatusOK)
+		case b == nil:
+			writeS3Error(w, r, http.StatusNotFound, "NoSuchBucket")
+		case r.Method == http.MethodGet:
+			s3ListObjects(w, r, b)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeS3Error(w, r, http.StatusNotImplemented, "NotImplemented")
+		}
+		return
+	}
+
+	if b == nil {
+		writeS3Error(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := randomToken()
+		b.uploads[uploadID] = map[int][]byte{}
+		writeXML(w, http.StatusOK, s3InitiateUploadResult{Bucket: bucketName, Key: key, UploadID: uploadID})
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := b.uploads[query.Get("uploadId")]
+		partNumber, err := strconv.Atoi(query.Get("partNumber"))
+		if !ok || err != nil {
+			writeS3Error(w, r, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		parts[partNumber] = data
+		w.Header().Set("ETag", (&object{data: data}).etag())
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		parts, ok := b.uploads[query.Get("uploadId")]
+		if !ok {
+			writeS3Error(w, r, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		numbers := make([]int, 0, len(parts))
+		for number := range parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		var data bytes.Buffer
+		for _, number := range numbers {
+			data.Write(parts[number])
+		}
+		delete(b.uploads, query.Get("uploadId"))
+		o := &object{data: data.Bytes(), modified: time.Now()}
+		b.objects[key] = o
+		writeXML(w, http.StatusOK, s3CompleteUploadResult{Bucket: bucketName, Key: key, ETag: o.etag()})
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(b.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		o := &object{data: data, modified: time.Now()}
+		b.objects[key] = o
+		w.Header().Set("ETag", o.etag())
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		o := b.objects[key]
+		if o == nil {
+			writeS3Error(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", o.etag())
+		w.Header().Set("Content-Type", "application/octet-stream")
+		// ServeContent handles the range requests of the downloads in parts
+		http.ServeContent(w, r, key, o.modified, bytes.NewReader(o.data))
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// s3ListObjects lists the objects of a bucket with the ListObjects (V1 and V2) API
+func s3ListObjects(w http.ResponseWriter, r *http.Request, b *bucket) {
+	query := r.URL.Query()
+	v2 := query.Get("list-type") == "2"
+	result := s3ListResult{
+		Name:      b.name,
+		Prefix:    query.Get("prefix"),
+		Delimiter: query.Get("delimiter"),
+		MaxKeys:   1000,
+	}
+	if maxKeys, err := strconv.Atoi(query.Get("max-keys")); err == nil && maxKeys > 0 && maxKeys < result.MaxKeys {
+		result.MaxKeys = maxKeys
+	}
+	after := query.Get("marker")
+	if v2 {
+		result.ContinuationToken = query.Get("continuation-token")
+		after = result.ContinuationToken
+		if after == "" {
+			after = query.Get("start-after")
+		}
+	} else {
+		result.Marker = after
+	}
+
+	seenPrefixes := map[string]bool{}
+	last := ""
+	for _, key := range b.sortedKeys() {
+		if key <= after || !strings.HasPrefix(key, result.Prefix) {
+			continue
+		}
+		if len(result.Contents)+len(result.CommonPrefixes) == result.MaxKeys {
+			result.IsTruncated = true
+			break
+		}
+		last = key
+		if result.Delimiter != "" {
+			if i := strings.Index(key[len(result.Prefix):], result.Delimiter); i >= 0 {
+				prefix := key[:len(result.Prefix)+i+len(result.Delimiter)]
+				if !seenPrefixes[prefix] {
+					seenPrefixes[prefix] = true
+					result.CommonPrefixes = append(result.CommonPrefixes, s3Prefix{Prefix: prefix})
+				}
+				continue
+			}
+		}
+		o := b.objects[key]
+		result.Contents = append(result.Contents, s3Object{
+			Key:          key,
+			LastModified: o.modified.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         o.etag(),
+			Size:         int64(len(o.data)),
+			StorageClass: "STANDARD",
+		})
+	}
+
+	if result.IsTruncated {
+		if v2 {
+			result.NextContinuationToken = last
+		} else {
+			result.NextMarker = last
+		}
+	}
+	if v2 {
+		result.KeyCount = len(result.Contents) + len(result.CommonPrefixes)
+	}
+	writeXML(w, http.StatusOK, result)
+}
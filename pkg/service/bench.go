@@ -0,0 +1,194 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+)
+
+// BenchStatusConnectionError is the status of the benchmark requests that couldn't reach the cluster
+const BenchStatusConnectionError = "connection error"
+
+// BenchOptions configures a benchmark of a synchronous service
+type BenchOptions struct {
+	Requests    int
+	Concurrency int
+	// Token and Endpoint invoke the service of a non registered cluster, as in RunService
+	Token    string
+	Endpoint string
+	// Input is sent base64 encoded in every request
+	Input []byte
+}
+
+// BenchResult summarizes a benchmark of a synchronous service
+type BenchResult struct {
+	Requests        int     `json:"requests"`
+	Succeeded       int     `json:"succeeded"`
+	Failed          int     `json:"failed"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	// Throughput is the number of requests completed per second
+	Throughput float64 `json:"throughput"`
+	// Latency of the successful requests
+	Latency BenchLatency `json:"latency_seconds"`
+	// Errors counts the failed requests by status code
+	Errors  map[string]int `json:"errors"`
+	Samples []BenchSample  `json:"-"`
+}
+
+// BenchLatency contains latency statistics in seconds
+type BenchLatency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// BenchSample is the outcome of a single benchmark request
+type BenchSample struct {
+	Request        int       `json:"request"`
+	Start          time.Time `json:"start"`
+	LatencySeconds float64   `json:"latency_seconds"`
+	Status         string    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Bench invokes a synchronous service the given number of requests with the given concurrency, returning
+// the throughput, latency percentiles and the errors by status code
+func Bench(ctx context.Context, c *cluster.Cluster, name string, opts BenchOptions) (*BenchResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.Requests < 1 {
+		return nil, errors.New("the number of requests must be greater than zero")
+	}
+	if opts.Concurrency < 1 {
+		return nil, errors.New("the concurrency must be greater than zero")
+	}
+	input := base64.StdEncoding.EncodeToString(opts.Input)
+
+	samples := make([]BenchSample, opts.Requests)
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				samples[index] = benchRequest(ctx, c, name, opts, input, index)
+			}
+		}()
+	}
+
+	sent := 0
+	for ; sent < opts.Requests; sent++ {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- sent
+	}
+	close(queue)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return summarizeBench(samples[:sent], elapsed), ctx.Err()
+}
+
+func benchRequest(ctx context.Context, c *cluster.Cluster, name string, opts BenchOptions, input string, index int) BenchSample {
+	sample := BenchSample{Request: index + 1, Start: time.Now()}
+	// Retrying the failed requests would hide them from the results, and the requests already sent
+	// are completed when the bench is interrupted
+	body, err := RunServiceWithOptions(c, name, bytes.NewBufferString(input), RunOptions{
+		Token:    opts.Token,
+		Endpoint: opts.Endpoint,
+		Context:  cluster.WithoutRetries(context.WithoutCancel(ctx)),
+	})
+	if err == nil {
+		_, err = io.Copy(io.Discard, body)
+		body.Close()
+	}
+	sample.LatencySeconds = time.Since(sample.Start).Seconds()
+
+	var apiErr *cluster.APIError
+	switch {
+	case err == nil:
+		sample.Status = strconv.Itoa(http.StatusOK)
+	case errors.As(err, &apiErr):
+		sample.Status = strconv.Itoa(apiErr.StatusCode)
+	default:
+		sample.Status = BenchStatusConnectionError
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	return sample
+}
+
+func summarizeBench(samples []BenchSample, elapsed time.Duration) *BenchResult {
+	result := &BenchResult{
+		Requests:        len(samples),
+		DurationSeconds: elapsed.Seconds(),
+		Errors:          map[string]int{},
+		Samples:         samples,
+	}
+	if elapsed > 0 {
+		result.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+
+	latencies := []time.Duration{}
+	var total time.Duration
+	for _, sample := range samples {
+		if sample.Error != "" {
+			result.Failed++
+			result.Errors[sample.Status]++
+			continue
+		}
+		result.Succeeded++
+		latency := time.Duration(sample.LatencySeconds * float64(time.Second))
+		latencies = append(latencies, latency)
+		total += latency
+	}
+
+	if len(latencies) == 0 {
+		return result
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.Latency = BenchLatency{
+		Min:  latencies[0].Seconds(),
+		Mean: (total / time.Duration(len(latencies))).Seconds(),
+		P50:  Percentile(latencies, 50).Seconds(),
+		P90:  Percentile(latencies, 90).Seconds(),
+		P95:  Percentile(latencies, 95).Seconds(),
+		P99:  Percentile(latencies, 99).Seconds(),
+		Max:  latencies[len(latencies)-1].Seconds(),
+	}
+	return result
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// ChangeType describes how a field differs between two service definitions
+type ChangeType string
+
+const (
+	// FieldAdded the field is only set in the desired definition
+	FieldAdded ChangeType = "added"
+	// FieldRemoved the field is only set in the deployed definition
+	FieldRemoved ChangeType = "removed"
+	// FieldModified the field is set in both definitions with different values
+	FieldModified ChangeType = "modified"
+)
+
+const sensitiveValue = "(sensitive value)"
+
+// FieldChange represents a single field-level difference between two service definitions
+type FieldChange struct {
+	Path string     `json:"path"`
+	Type ChangeType `json:"type"`
+	Old  string     `json:"old,omitempty"`
+	New  string     `json:"new,omitempty"`
+}
+
+// ignoredDiffPaths contains the fields generated or managed by the cluster, which are never part of an FDL
+var ignoredDiffPaths = []string{
+	"token",
+	"owner",
+	"cluster_id",
+	"clusters",
+	"storage_providers.minio.default",
+}
+
+// DiffServices compares the deployed definition of a service with the desired one and returns the differences sorted by path.
+// The fields only set in the deployed definition are reported as removed if the map or list containing them is defined
+// in the desired one; otherwise they are considered filled in by the cluster
+func DiffServices(current, desired *types.Service) ([]FieldChange, error) {
+	currentFields, err := flattenService(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := flattenService(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(currentFields)+len(desiredFields))
+	for p := range currentFields {
+		paths = append(paths, p)
+	}
+	for p := range desiredFields {
+		if _, ok := currentFields[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	desiredContainers := containerPaths(desiredFields)
+
+	changes := []FieldChange{}
+	for _, p := range paths {
+		if isIgnoredDiffPath(p) {
+			continue
+		}
+		oldValue, inCurrent := currentFields[p]
+		newValue, inDesired := desiredFields[p]
+
+		change := FieldChange{Path: p}
+		switch {
+		case inCurrent && !inDesired && !desiredContainers[parentPath(p)]:
+			// Fields filled in by the cluster (defaults, generated values...) which the desired definition doesn't set
+			continue
+		case inCurrent && !inDesired:
+			change.Type = FieldRemoved
+			change.Old = oldValue
+		case !inCurrent && inDesired:
+			change.Type = FieldAdded
+			change.New = newValue
+		case oldValue != newValue:
+			change.Type = FieldModified
+			change.Old = oldValue
+			change.New = newValue
+		default:
+			continue
+		}
+
+		if isSensitivePath(p) {
+			if change.Old != "" {
+				change.Old = sensitiveValue
+			}
+			if change.New != "" {
+				change.New = sensitiveValue
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func flattenService(svc *types.Service) (map[string]string, error) {
+	fields := map[string]string{}
+	if svc == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(svc)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the service \"%s\", please check its definition", svc.Name)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+
+	flattenValue("", generic, fields)
+	return fields, nil
+}
+
+// flattenValue walks a decoded JSON value storing every non-empty leaf under its dotted path
+func flattenValue(prefix string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if prefix != "" {
+				childPath = prefix + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenValue(fmt.Sprintf("%s[%d]", prefix, i), child, fields)
+		}
+	case string:
+		if v != "" {
+			fields[prefix] = fmt.Sprintf("%q", v)
+		}
+	case bool:
+		if v {
+			fields[prefix] = "true"
+		}
+	case float64:
+		if v != 0 {
+			fields[prefix] = fmt.Sprint(v)
+		}
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
+
+// containerPaths returns the maps and lists that contain the given fields, at any level
+func containerPaths(fields map[string]string) map[string]bool {
+	containers := map[string]bool{}
+	for p := range fields {
+		for parent := parentPath(p); parent != "" && !containers[parent]; parent = parentPath(parent) {
+			containers[parent] = true
+		}
+	}
+	return containers
+}
+
+// parentPath returns the map or list containing a field, "" for the top-level fields. The items of a list
+// belong to the list itself, so removing an item is reported if the list is defined
+func parentPath(p string) string {
+	i := strings.LastIndexAny(p, ".[")
+	if i < 0 {
+		return ""
+	}
+	parent := p[:i]
+	if strings.HasSuffix(parent, "]") {
+		if j := strings.LastIndex(parent, "["); j >= 0 {
+			parent = parent[:j]
+		}
+	}
+	return parent
+}
+
+func isIgnoredDiffPath(p string) bool {
+	for _, ignored := range ignoredDiffPaths {
+		if p == ignored || strings.HasPrefix(p, ignored+".") || strings.HasPrefix(p, ignored+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitivePath(p string) bool {
+	lower := strings.ToLower(p)
+	return strings.Contains(lower, "secret") || strings.Contains(lower, "password") || strings.Contains(lower, "token")
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func TestDiffServices(t *testing.T) {
+	current := &types.Service{
+		Name:   "demo",
+		Image:  "ghcr.io/demo/app:1.0",
+		Memory: "256Mi",
+		CPU:    "0.5",
+		Token:  "generated-token",
+		Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+	}
+	desired := &types.Service{
+		Name:   "demo",
+		Image:  "ghcr.io/demo/app:1.1",
+		Memory: "256Mi",
+		Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+		Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/out"}},
+	}
+
+	changes, err := DiffServices(current, desired)
+	if err != nil {
+		t.Fatalf("DiffServices returned error: %v", err)
+	}
+
+	byPath := map[string]FieldChange{}
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+
+	if _, ok := byPath["token"]; ok {
+		t.Fatalf("expected token to be ignored, got %+v", changes)
+	}
+	if change, ok := byPath["image"]; !ok || change.Type != FieldModified || change.Old != `"ghcr.io/demo/app:1.0"` || change.New != `"ghcr.io/demo/app:1.1"` {
+		t.Fatalf("unexpected image change: %+v", change)
+	}
+	if change, ok := byPath["cpu"]; ok {
+		t.Fatalf("expected cpu not set in the desired definition to be ignored, got %+v", change)
+	}
+	if change, ok := byPath["output[0].path"]; !ok || change.Type != FieldAdded || change.New != `"demo/out"` {
+		t.Fatalf("expected output path to be added, got %+v", change)
+	}
+	if _, ok := byPath["input[0].path"]; ok {
+		t.Fatalf("unexpected change in unchanged input path")
+	}
+}
+
+func TestDiffServicesIdentical(t *testing.T) {
+	svc := &types.Service{Name: "demo", Image: "img", Memory: "1Gi"}
+
+	changes, err := DiffServices(svc, svc)
+	if err != nil {
+		t.Fatalf("DiffServices returned error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestDiffServicesServerPopulatedFields(t *testing.T) {
+	// Service as returned by the cluster, with the defaults and generated fields filled in
+	current := &types.Service{}
+	if err := json.Unmarshal([]byte(`{
+		"name": "demo",
+		"image": "ghcr.io/demo/app:1.0",
+		"memory": "256Mi",
+		"cpu": "0.2",
+		"log_level": "INFO",
+		"token": "generated-token",
+		"environment": {"variables": {"EXISTING": "1", "OTHER": "2"}, "secrets": {}},
+		"storage_providers": {"minio": {"default": {"endpoint": "https://minio.example.com"}}, "s3": {}},
+		"input": [{"storage_provider": "minio.default", "path": "demo/in"}, {"storage_provider": "minio.default", "path": "demo/extra"}],
+		"labels": {"oscar-cli/fdl": "fdl"}
+	}`), current); err != nil {
+		t.Fatalf("decoding service: %v", err)
+	}
+	desired := &types.Service{}
+	if err := json.Unmarshal([]byte(`{
+		"name": "demo",
+		"image": "ghcr.io/demo/app:1.0",
+		"memory": "256Mi",
+		"environment": {"variables": {"EXISTING": "1"}},
+		"input": [{"storage_provider": "minio.default", "path": "demo/in"}]
+	}`), desired); err != nil {
+		t.Fatalf("decoding service: %v", err)
+	}
+
+	changes, err := DiffServices(current, desired)
+	if err != nil {
+		t.Fatalf("DiffServices returned error: %v", err)
+	}
+
+	byPath := map[string]FieldChange{}
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+	if change, ok := byPath["environment.variables.OTHER"]; !ok || change.Type != FieldRemoved {
+		t.Fatalf("expected the variable removed from the definition to be reported, got %+v", changes)
+	}
+	if change, ok := byPath["input[1].path"]; !ok || change.Type != FieldRemoved {
+		t.Fatalf("expected the input removed from the definition to be reported, got %+v", changes)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected only the removed variable and input, got %+v", changes)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/goccy/go-yaml"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+const scriptExtension = ".sh"
+
+// ExportFDL builds an FDL with the definitions of services deployed in a cluster, stripping the fields
+// generated by the cluster (tokens, owners, cluster credentials and the injected MinIO provider).
+// The storage providers of the services are moved to the FDL and their scripts are returned indexed by
+// the file name referenced from the FDL, so they can be written next to it
+func ExportFDL(clusterID string, services []*types.Service) (*FDL, map[string]string, error) {
+	fdl := &FDL{}
+	scripts := map[string]string{}
+	providers := map[string]map[string]json.RawMessage{}
+
+	for _, original := range services {
+		if original == nil {
+			continue
+		}
+		svc, err := PortableService(original)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := mergeStorageProviders(providers, svc.StorageProviders); err != nil {
+			return nil, nil, err
+		}
+		svc.StorageProviders = nil
+
+		scriptName := svc.Name + scriptExtension
+		scripts[scriptName] = svc.Script
+		svc.Script = scriptName
+
+		fdl.Functions.Oscar = append(fdl.Functions.Oscar, map[string]*types.Service{clusterID: svc})
+	}
+
+	if len(providers) > 0 {
+		content, err := json.Marshal(providers)
+		if err != nil {
+			return nil, nil, err
+		}
+		fdl.StorageProviders = &types.StorageProviders{}
+		if err := json.Unmarshal(content, fdl.StorageProviders); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return fdl, scripts, nil
+}
+
+// PortableService returns a deep copy of a deployed service without the fields generated by its cluster, so it can
+// be deployed in any cluster: the token, the owner, the cluster identifiers and credentials, the FDLLabel and the
+// MinIO provider injected by OSCAR. The copy is made through the JSON representation of the service, so changing
+// its storage paths or maps doesn't modify the deployed service
+func PortableService(deployed *types.Service) (*types.Service, error) {
+	content, err := json.Marshal(deployed)
+	if err != nil {
+		return nil, err
+	}
+	svc := &types.Service{}
+	if err := json.Unmarshal(content, svc); err != nil {
+		return nil, err
+	}
+
+	svc.Token = ""
+	svc.Owner = ""
+	svc.ClusterID = ""
+	svc.Clusters = nil
+
+	delete(svc.Labels, FDLLabel)
+	if svc.StorageProviders != nil {
+		delete(svc.StorageProviders.MinIO, "default")
+	}
+
+	return svc, nil
+}
+
+// mergeStorageProviders adds the providers defined by a service to the ones of the FDL
+func mergeStorageProviders(dst map[string]map[string]json.RawMessage, providers *types.StorageProviders) error {
+	if providers == nil {
+		return nil
+	}
+	content, err := json.Marshal(providers)
+	if err != nil {
+		return err
+	}
+	byType := map[string]map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &byType); err != nil {
+		return err
+	}
+	for providerType, ids := range byType {
+		for id, provider := range ids {
+			if bytes.Equal(provider, []byte("null")) {
+				continue
+			}
+			if dst[providerType] == nil {
+				dst[providerType] = map[string]json.RawMessage{}
+			}
+			dst[providerType][id] = provider
+		}
+	}
+	return nil
+}
+
+// MarshalFDL encodes an FDL in YAML omitting its empty fields
+func MarshalFDL(fdl *FDL) ([]byte, error) {
+	content, err := json.Marshal(fdl)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(pruneEmptyValues(document))
+}
+
+// pruneEmptyValues removes the null, zero and empty values of a decoded JSON document
+func pruneEmptyValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		pruned := map[string]interface{}{}
+		for key, child := range v {
+			if child = pruneEmptyValues(child); child != nil {
+				pruned[key] = child
+			}
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		return pruned
+	case []interface{}:
+		pruned := []interface{}{}
+		for _, child := range v {
+			if child = pruneEmptyValues(child); child != nil {
+				pruned = append(pruned, child)
+			}
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		return pruned
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			if i == 0 {
+				return nil
+			}
+			return i
+		}
+		f, _ := v.Float64()
+		if f == 0 {
+			return nil
+		}
+		return f
+	case string:
+		if v == "" {
+			return nil
+		}
+		return v
+	case bool:
+		if !v {
+			return nil
+		}
+		return v
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func TestExportFDLRoundTrip(t *testing.T) {
+	deployed := &types.Service{
+		Name:      "demo",
+		Image:     "ghcr.io/demo/app:1.0",
+		Memory:    "512Mi",
+		CPU:       "1",
+		Script:    "#!/bin/bash\necho demo\n",
+		Token:     "generated-token",
+		Owner:     "someone@example.org",
+		ClusterID: "remote-id",
+		Labels:    map[string]string{FDLLabel: "old", "team": "a"},
+		Input:     []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+		Output:    []types.StorageIOConfig{{Provider: "s3.results", Path: "demo-results/out"}},
+		StorageProviders: &types.StorageProviders{
+			MinIO: map[string]*types.MinIOProvider{"default": {Endpoint: "https://minio", AccessKey: "injected", SecretKey: "injected-secret"}},
+			S3:    map[string]*types.S3Provider{"results": {AccessKey: "key", SecretKey: "secret", Region: "us-east-1"}},
+		},
+		Clusters: map[string]types.Cluster{"remote": {Endpoint: "https://remote", AuthUser: "user", AuthPassword: "pass"}},
+	}
+
+	fdl, scripts, err := ExportFDL("my-cluster", []*types.Service{deployed})
+	if err != nil {
+		t.Fatalf("ExportFDL returned error: %v", err)
+	}
+	if deployed.Token != "generated-token" || deployed.Labels[FDLLabel] != "old" {
+		t.Fatalf("expected the original service to be left untouched")
+	}
+	if scripts["demo.sh"] != deployed.Script {
+		t.Fatalf("unexpected scripts: %v", scripts)
+	}
+
+	content, err := MarshalFDL(fdl)
+	if err != nil {
+		t.Fatalf("MarshalFDL returned error: %v", err)
+	}
+	for _, unexpected := range []string{"generated-token", "someone@example.org", "injected", "pass", FDLLabel, "enable_gpu"} {
+		if strings.Contains(string(content), unexpected) {
+			t.Fatalf("expected %q to be stripped from the FDL:\n%s", unexpected, content)
+		}
+	}
+
+	dir := t.TempDir()
+	fdlPath := filepath.Join(dir, "fdl.yaml")
+	if err := os.WriteFile(fdlPath, content, 0o600); err != nil {
+		t.Fatalf("writing fdl: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "demo.sh"), []byte(scripts["demo.sh"]), 0o700); err != nil {
+		t.Fatalf("writing script: %v", err)
+	}
+
+	read, err := ReadFDL(fdlPath)
+	if err != nil {
+		t.Fatalf("ReadFDL returned error: %v\n%s", err, content)
+	}
+	svc := read.Functions.Oscar[0]["my-cluster"]
+	if svc == nil {
+		t.Fatalf("expected service for cluster my-cluster:\n%s", content)
+	}
+	if svc.Name != "demo" || svc.Image != deployed.Image || svc.Memory != "512Mi" || svc.Script != deployed.Script || svc.Labels["team"] != "a" {
+		t.Fatalf("unexpected round-tripped service: %+v", svc)
+	}
+	if svc.StorageProviders == nil || svc.StorageProviders.S3["results"] == nil || svc.StorageProviders.S3["results"].SecretKey != "secret" {
+		t.Fatalf("expected the S3 provider to be preserved, got %+v", svc.StorageProviders)
+	}
+	if svc.StorageProviders.MinIO["default"] != nil {
+		t.Fatalf("expected the injected MinIO provider to be stripped")
+	}
+}
+
+func TestPortableServiceDeepCopy(t *testing.T) {
+	deployed := &types.Service{
+		Name:   "demo",
+		Labels: map[string]string{FDLLabel: "old", "team": "a"},
+		Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/in"}},
+		Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "demo/out"}},
+	}
+
+	svc, err := PortableService(deployed)
+	if err != nil {
+		t.Fatalf("PortableService returned error: %v", err)
+	}
+	svc.Input[0].Path = "renamed/in"
+	svc.Output[0].Path = "renamed/out"
+	svc.Labels["team"] = "b"
+
+	if deployed.Input[0].Path != "demo/in" || deployed.Output[0].Path != "demo/out" {
+		t.Fatalf("expected the deployed paths to be left untouched, got %+v %+v", deployed.Input, deployed.Output)
+	}
+	if deployed.Labels["team"] != "a" || deployed.Labels[FDLLabel] != "old" {
+		t.Fatalf("expected the deployed labels to be left untouched, got %v", deployed.Labels)
+	}
+}
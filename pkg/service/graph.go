@@ -0,0 +1,382 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// WorkflowNode is a service of an FDL workflow, identified by "<CLUSTER_ID>/<SERVICE_NAME>"
+type WorkflowNode struct {
+	ID      string `json:"id"`
+	Cluster string `json:"cluster"`
+	Name    string `json:"name"`
+}
+
+// WorkflowStorage is a storage path read or written by a service of a workflow
+type WorkflowStorage struct {
+	Provider string `json:"provider"`
+	Path     string `json:"path"`
+}
+
+// String returns the path, prefixed with its provider when it is not the MinIO of the service's cluster
+func (s WorkflowStorage) String() string {
+	if s.Provider == "" || isDefaultMinIOProvider(s.Provider) {
+		return s.Path
+	}
+	return s.Provider + ":" + s.Path
+}
+
+// WorkflowEdge links a service with another one that reads its output
+type WorkflowEdge struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Storage WorkflowStorage `json:"storage"`
+}
+
+// WorkflowEndpoint is an input or output of a service that is not connected to any other service
+type WorkflowEndpoint struct {
+	Node    string          `json:"node"`
+	Storage WorkflowStorage `json:"storage"`
+}
+
+// WorkflowGraph is the dependency graph of the services of an FDL file chained through their storage paths
+type WorkflowGraph struct {
+	Nodes []WorkflowNode `json:"nodes"`
+	Edges []WorkflowEdge `json:"edges"`
+	// Sources are the inputs not written by any service of the workflow
+	Sources []WorkflowEndpoint `json:"sources"`
+	// Unconsumed are the outputs not read by any service of the workflow
+	Unconsumed []WorkflowEndpoint `json:"unconsumed"`
+	// Cycles contains the identifiers of the services that trigger each other
+	Cycles [][]string `json:"cycles"`
+}
+
+type workflowEndpoint struct {
+	node     string
+	location string
+	path     string
+	storage  WorkflowStorage
+}
+
+// BuildWorkflowGraph builds the dependency graph of an FDL. A service depends on another one when one of its
+// inputs is the path (or a parent of the path) of one of the other's outputs in the same storage. MinIO
+// providers named after a cluster of the FDL (e.g. "minio.oscar-replica") refer to the MinIO of that cluster
+func BuildWorkflowGraph(fdl *FDL) *WorkflowGraph {
+	g := &WorkflowGraph{
+		Nodes:      []WorkflowNode{},
+		Edges:      []WorkflowEdge{},
+		Sources:    []WorkflowEndpoint{},
+		Unconsumed: []WorkflowEndpoint{},
+		Cycles:     [][]string{},
+	}
+	if fdl == nil {
+		return g
+	}
+
+	type workflowService struct {
+		node WorkflowNode
+		svc  *types.Service
+	}
+	services := []workflowService{}
+	clusterIDs := map[string]bool{}
+	for _, element := range fdl.Functions.Oscar {
+		ids := make([]string, 0, len(element))
+		for clusterID := range element {
+			ids = append(ids, clusterID)
+		}
+		sort.Strings(ids)
+
+		for _, clusterID := range ids {
+			svc := element[clusterID]
+			if svc == nil {
+				continue
+			}
+			clusterIDs[clusterID] = true
+			node := WorkflowNode{ID: clusterID + "/" + svc.Name, Cluster: clusterID, Name: svc.Name}
+			services = append(services, workflowService{node: node, svc: svc})
+			g.Nodes = append(g.Nodes, node)
+		}
+	}
+
+	inputs := []workflowEndpoint{}
+	outputs := []workflowEndpoint{}
+	for _, s := range services {
+		for _, input := range s.svc.Input {
+			inputs = append(inputs, newWorkflowEndpoint(s.node, input, clusterIDs))
+		}
+		for _, output := range s.svc.Output {
+			outputs = append(outputs, newWorkflowEndpoint(s.node, output, clusterIDs))
+		}
+	}
+
+	for _, output := range outputs {
+		consumed := false
+		for _, input := range inputs {
+			if output.feeds(input) {
+				consumed = true
+				g.Edges = append(g.Edges, WorkflowEdge{From: output.node, To: input.node, Storage: output.storage})
+			}
+		}
+		if !consumed {
+			g.Unconsumed = append(g.Unconsumed, WorkflowEndpoint{Node: output.node, Storage: output.storage})
+		}
+	}
+
+	for _, input := range inputs {
+		produced := false
+		for _, output := range outputs {
+			if output.feeds(input) {
+				produced = true
+				break
+			}
+		}
+		if !produced {
+			g.Sources = append(g.Sources, WorkflowEndpoint{Node: input.node, Storage: input.storage})
+		}
+	}
+
+	g.Cycles = findWorkflowCycles(g.Nodes, g.Edges)
+
+	return g
+}
+
+func newWorkflowEndpoint(node WorkflowNode, config types.StorageIOConfig, clusterIDs map[string]bool) workflowEndpoint {
+	provider := strings.TrimSpace(config.Provider)
+	path := strings.Trim(strings.TrimSpace(config.Path), "/")
+
+	// Identify the storage where the path is located
+	location := provider
+	if provider == "" || isDefaultMinIOProvider(provider) {
+		location = types.MinIOName + "@" + node.Cluster
+	} else if parts := strings.SplitN(provider, types.ProviderSeparator, 2); len(parts) == 2 && parts[0] == types.MinIOName && clusterIDs[parts[1]] {
+		location = types.MinIOName + "@" + parts[1]
+	}
+
+	return workflowEndpoint{
+		node:     node.ID,
+		location: location,
+		path:     path,
+		storage:  WorkflowStorage{Provider: provider, Path: path},
+	}
+}
+
+// feeds returns true if the files uploaded to the output trigger the service of the input
+func (output workflowEndpoint) feeds(input workflowEndpoint) bool {
+	if output.location != input.location || output.path == "" || input.path == "" {
+		return false
+	}
+	return output.path == input.path || strings.HasPrefix(output.path, input.path+"/")
+}
+
+// findWorkflowCycles returns the strongly connected components of the graph that contain a cycle
+func findWorkflowCycles(nodes []WorkflowNode, edges []WorkflowEdge) [][]string {
+	order := map[string]int{}
+	for i, node := range nodes {
+		order[node.ID] = i
+	}
+	adjacency := map[string][]string{}
+	selfLoops := map[string]bool{}
+	for _, edge := range edges {
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+		if edge.From == edge.To {
+			selfLoops[edge.From] = true
+		}
+	}
+
+	cycles := [][]string{}
+	index := 0
+	indices := map[string]int{}
+	lowlinks := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+
+	var connect func(id string)
+	connect = func(id string) {
+		indices[id] = index
+		lowlinks[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range adjacency[id] {
+			if _, visited := indices[next]; !visited {
+				connect(next)
+				lowlinks[id] = min(lowlinks[id], lowlinks[next])
+			} else if onStack[next] {
+				lowlinks[id] = min(lowlinks[id], indices[next])
+			}
+		}
+
+		if lowlinks[id] != indices[id] {
+			return
+		}
+		component := []string{}
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append(component, last)
+			if last == id {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoops[id] {
+			sort.Slice(component, func(i, j int) bool { return order[component[i]] < order[component[j]] })
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := indices[node.ID]; !visited {
+			connect(node.ID)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return order[cycles[i][0]] < order[cycles[j][0]] })
+
+	return cycles
+}
+
+// inCycle returns true if both services of an edge belong to the same cycle
+func (g *WorkflowGraph) inCycle(edge WorkflowEdge) bool {
+	for _, cycle := range g.Cycles {
+		var from, to bool
+		for _, id := range cycle {
+			from = from || id == edge.From
+			to = to || id == edge.To
+		}
+		if from && to {
+			return true
+		}
+	}
+	return false
+}
+
+// clusters returns the cluster identifiers of the graph in order of appearance
+func (g *WorkflowGraph) clusters() []string {
+	seen := map[string]bool{}
+	clusters := []string{}
+	for _, node := range g.Nodes {
+		if !seen[node.Cluster] {
+			seen[node.Cluster] = true
+			clusters = append(clusters, node.Cluster)
+		}
+	}
+	return clusters
+}
+
+// DOT renders the graph in the Graphviz DOT language, grouping the services by cluster
+func (g *WorkflowGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for i, clusterID := range g.clusters() {
+		fmt.Fprintf(&b, "  subgraph \"cluster_%d\" {\n", i)
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(clusterID))
+		for _, node := range g.Nodes {
+			if node.Cluster == clusterID {
+				fmt.Fprintf(&b, "    %s [label=%s];\n", dotQuote(node.ID), dotQuote(node.Name))
+			}
+		}
+		b.WriteString("  }\n")
+	}
+
+	for i, source := range g.Sources {
+		id := fmt.Sprintf("source_%d", i)
+		fmt.Fprintf(&b, "  %s [label=%s, shape=folder];\n", id, dotQuote(source.Storage.String()))
+		fmt.Fprintf(&b, "  %s -> %s;\n", id, dotQuote(source.Node))
+	}
+
+	for _, edge := range g.Edges {
+		attributes := "label=" + dotQuote(edge.Storage.String())
+		if g.inCycle(edge) {
+			attributes += ", color=red"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), attributes)
+	}
+
+	for i, output := range g.Unconsumed {
+		id := fmt.Sprintf("output_%d", i)
+		fmt.Fprintf(&b, "  %s [label=%s, shape=folder, style=dashed];\n", id, dotQuote(output.Storage.String()))
+		fmt.Fprintf(&b, "  %s -> %s;\n", dotQuote(output.Node), id)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotQuote(value string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(value) + "\""
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, grouping the services by cluster
+func (g *WorkflowGraph) Mermaid() string {
+	ids := map[string]string{}
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	for i, clusterID := range g.clusters() {
+		fmt.Fprintf(&b, "  subgraph c%d [%s]\n", i, mermaidQuote(clusterID))
+		for _, node := range g.Nodes {
+			if node.Cluster == clusterID {
+				fmt.Fprintf(&b, "    %s[%s]\n", ids[node.ID], mermaidQuote(node.Name))
+			}
+		}
+		b.WriteString("  end\n")
+	}
+
+	links := 0
+	cycleLinks := []string{}
+	for i, source := range g.Sources {
+		fmt.Fprintf(&b, "  s%d[(%s)] --> %s\n", i, mermaidQuote(source.Storage.String()), ids[source.Node])
+		links++
+	}
+
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[edge.From], mermaidQuote(edge.Storage.String()), ids[edge.To])
+		if g.inCycle(edge) {
+			cycleLinks = append(cycleLinks, fmt.Sprint(links))
+		}
+		links++
+	}
+
+	for i, output := range g.Unconsumed {
+		fmt.Fprintf(&b, "  %s -.-> o%d[(%s)]\n", ids[output.Node], i, mermaidQuote(output.Storage.String()))
+		links++
+	}
+
+	if len(cycleLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red\n", strings.Join(cycleLinks, ","))
+	}
+
+	return b.String()
+}
+
+func mermaidQuote(value string) string {
+	return "\"" + strings.ReplaceAll(value, "\"", "#quot;") + "\""
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+func newWorkflowFDL(services map[string][]*types.Service) *FDL {
+	fdl := &FDL{}
+	for _, clusterID := range []string{"oscar-a", "oscar-b"} {
+		for _, svc := range services[clusterID] {
+			fdl.Functions.Oscar = append(fdl.Functions.Oscar, map[string]*types.Service{clusterID: svc})
+		}
+	}
+	return fdl
+}
+
+func TestBuildWorkflowGraph(t *testing.T) {
+	fdl := newWorkflowFDL(map[string][]*types.Service{
+		"oscar-a": {
+			{
+				Name:   "plants",
+				Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "workflow/in"}},
+				Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "workflow/med"}, {Provider: "minio.oscar-b", Path: "remote/in"}},
+			},
+			{
+				Name:   "grayify",
+				Input:  []types.StorageIOConfig{{Provider: "minio", Path: "/workflow/med/"}},
+				Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "workflow/res"}},
+			},
+		},
+		"oscar-b": {
+			{
+				Name:  "remote",
+				Input: []types.StorageIOConfig{{Provider: "minio.default", Path: "remote"}},
+			},
+		},
+	})
+
+	graph := BuildWorkflowGraph(fdl)
+
+	if len(graph.Nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %+v", graph.Nodes)
+	}
+	edges := map[string]string{}
+	for _, edge := range graph.Edges {
+		edges[edge.From+" -> "+edge.To] = edge.Storage.String()
+	}
+	if edges["oscar-a/plants -> oscar-a/grayify"] != "workflow/med" {
+		t.Fatalf("expected plants to feed grayify, got %v", edges)
+	}
+	if edges["oscar-a/plants -> oscar-b/remote"] != "minio.oscar-b:remote/in" {
+		t.Fatalf("expected plants to feed the remote service, got %v", edges)
+	}
+	if len(graph.Edges) != 2 {
+		t.Fatalf("expected 2 edges, got %v", edges)
+	}
+	if len(graph.Sources) != 1 || graph.Sources[0].Storage.Path != "workflow/in" {
+		t.Fatalf("unexpected sources: %+v", graph.Sources)
+	}
+	if len(graph.Unconsumed) != 1 || graph.Unconsumed[0].Node != "oscar-a/grayify" || graph.Unconsumed[0].Storage.Path != "workflow/res" {
+		t.Fatalf("unexpected unconsumed outputs: %+v", graph.Unconsumed)
+	}
+	if len(graph.Cycles) != 0 {
+		t.Fatalf("expected no cycles, got %+v", graph.Cycles)
+	}
+
+	dot := graph.DOT()
+	if !strings.Contains(dot, `"oscar-a/plants" -> "oscar-a/grayify" [label="workflow/med"];`) || !strings.Contains(dot, `subgraph "cluster_1"`) {
+		t.Fatalf("unexpected DOT output:\n%s", dot)
+	}
+	mermaid := graph.Mermaid()
+	if !strings.HasPrefix(mermaid, "flowchart LR\n") || !strings.Contains(mermaid, `n0 -->|"workflow/med"| n1`) {
+		t.Fatalf("unexpected Mermaid output:\n%s", mermaid)
+	}
+}
+
+func TestBuildWorkflowGraphCycles(t *testing.T) {
+	fdl := newWorkflowFDL(map[string][]*types.Service{
+		"oscar-a": {
+			{
+				Name:   "first",
+				Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "loop/a"}},
+				Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "loop/b"}},
+			},
+			{
+				Name:   "second",
+				Input:  []types.StorageIOConfig{{Provider: "minio.default", Path: "loop/b"}},
+				Output: []types.StorageIOConfig{{Provider: "minio.default", Path: "loop/a/again"}},
+			},
+		},
+		"oscar-b": {
+			{
+				// Same path in a different cluster, not connected
+				Name:  "other",
+				Input: []types.StorageIOConfig{{Provider: "minio.default", Path: "loop/b"}},
+			},
+		},
+	})
+
+	graph := BuildWorkflowGraph(fdl)
+
+	if len(graph.Cycles) != 1 || strings.Join(graph.Cycles[0], ",") != "oscar-a/first,oscar-a/second" {
+		t.Fatalf("unexpected cycles: %+v", graph.Cycles)
+	}
+	if len(graph.Edges) != 2 {
+		t.Fatalf("expected 2 edges, got %+v", graph.Edges)
+	}
+	if !strings.Contains(graph.DOT(), "color=red") || !strings.Contains(graph.Mermaid(), "linkStyle 1,2 stroke:red") {
+		t.Fatalf("expected cycle edges to be highlighted")
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// ErrJobNotIdentified is returned when the job created by an invocation can't be found in the cluster
+var ErrJobNotIdentified = errors.New("unable to identify the job created by the invocation")
+
+// jobNameFromResponse returns the job name included in the response of an asynchronous invocation, if any
+func jobNameFromResponse(body []byte) string {
+	var response map[string]interface{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return ""
+	}
+	for _, key := range []string{"jobName", "job_name", "job", "name"} {
+		if name, ok := response[key].(string); ok && strings.TrimSpace(name) != "" {
+			return strings.TrimSpace(name)
+		}
+	}
+	return ""
+}
+
+// FindNewJob polls the logs of a service every interval until a job not present in before appears, returning the most recent one
+func FindNewJob(ctx context.Context, c *cluster.Cluster, svcName string, before map[string]*types.JobInfo, interval time.Duration) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		current, err := ListAllLogsWithContext(ctx, c, svcName)
+		if err != nil {
+			return "", contextError(ctx, err)
+		}
+
+		newest := ""
+		var newestTime time.Time
+		for name, info := range current {
+			if _, ok := before[name]; ok {
+				continue
+			}
+			jobTime := JobTimestamp(info)
+			if newest == "" || jobTime.After(newestTime) || (jobTime.Equal(newestTime) && name > newest) {
+				newest = name
+				newestTime = jobTime
+			}
+		}
+		if newest != "" {
+			return newest, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ErrJobNotIdentified
+		case <-time.After(interval):
+		}
+	}
+}
+
+// WaitForJob polls the status of a service's job every interval until it reaches a terminal status, returning its final info
+func WaitForJob(ctx context.Context, c *cluster.Cluster, svcName, jobName string, interval time.Duration) (*types.JobInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		info, err := GetJobInfoWithContext(ctx, c, svcName, jobName)
+		if err != nil && !errors.Is(err, ErrJobNotFound) {
+			return nil, contextError(ctx, err)
+		}
+		if IsJobFinished(info) {
+			return info, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return info, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// contextError returns the error of the context if it's done, as the requests in progress fail when it's cancelled
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// ReadJobResponse reads and closes the response of an asynchronous invocation, returning the job name included in it, if any
+func ReadJobResponse(body io.ReadCloser) (string, error) {
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return jobNameFromResponse(content), nil
+}
@@ -18,13 +18,16 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/grycap/oscar-cli/pkg/cluster"
@@ -39,10 +42,26 @@ type JobsResponse struct {
 	RemainingJob *int64                    `json:"remaining_jobs,omitempty"`
 }
 
-var ErrNoLogsFound = errors.New("service has no logs")
+// Job statuses reported by the logs endpoint
+const (
+	JobPending   = "Pending"
+	JobRunning   = "Running"
+	JobSucceeded = "Succeeded"
+	JobFailed    = "Failed"
+)
+
+var (
+	ErrNoLogsFound = errors.New("service has no logs")
+	ErrJobNotFound = errors.New("job not found")
+)
 
 // ListLogs returns a map with all the available logs from the given service
 func ListLogs(c *cluster.Cluster, name string, page string) (logMap JobsResponse, err error) {
+	return ListLogsWithContext(context.Background(), c, name, page)
+}
+
+// ListLogsWithContext returns a map with all the available logs from the given service honouring context cancellation
+func ListLogsWithContext(ctx context.Context, c *cluster.Cluster, name string, page string) (logMap JobsResponse, err error) {
 	listLogsURL, err := url.Parse(c.Endpoint)
 	if err != nil {
 		return logMap, cluster.ErrParsingEndpoint
@@ -51,7 +70,7 @@ func ListLogs(c *cluster.Cluster, name string, page string) (logMap JobsResponse
 	query := listLogsURL.Query()
 	query.Set("page", page)
 	listLogsURL.RawQuery = query.Encode()
-	req, err := http.NewRequest(http.MethodGet, listLogsURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, listLogsURL.String(), nil)
 	if err != nil {
 		return logMap, cluster.ErrMakingRequest
 	}
@@ -100,8 +119,44 @@ func ListLogs(c *cluster.Cluster, name string, page string) (logMap JobsResponse
 	return jobsResponse, nil
 }
 
+// ListAllLogs returns the logs of a service going through all the pages
+func ListAllLogs(c *cluster.Cluster, name string) (map[string]*types.JobInfo, error) {
+	return ListAllLogsWithContext(context.Background(), c, name)
+}
+
+// ListAllLogsWithContext returns the logs of a service going through all the pages honouring context cancellation
+func ListAllLogsWithContext(ctx context.Context, c *cluster.Cluster, name string) (map[string]*types.JobInfo, error) {
+	logMap, err := ListLogsWithContext(ctx, c, name, "")
+	if err != nil {
+		return nil, err
+	}
+	allLogs := logMap.Jobs
+	if allLogs == nil {
+		allLogs = map[string]*types.JobInfo{}
+	}
+
+	for logMap.NextPage != "" {
+		logMap, err = ListLogsWithContext(ctx, c, name, logMap.NextPage)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add all the logs of the next page
+		for k, v := range logMap.Jobs {
+			allLogs[k] = v
+		}
+	}
+
+	return allLogs, nil
+}
+
 // GetLogs get the logs from a service's job
 func GetLogs(c *cluster.Cluster, svcName string, jobName string, timestamps bool) (logs string, err error) {
+	return GetLogsWithContext(context.Background(), c, svcName, jobName, timestamps)
+}
+
+// GetLogsWithContext get the logs from a service's job honouring context cancellation
+func GetLogsWithContext(ctx context.Context, c *cluster.Cluster, svcName string, jobName string, timestamps bool) (logs string, err error) {
 	getLogsURL, err := url.Parse(c.Endpoint)
 	if err != nil {
 		return logs, cluster.ErrParsingEndpoint
@@ -114,7 +169,7 @@ func GetLogs(c *cluster.Cluster, svcName string, jobName string, timestamps bool
 		getLogsURL.RawQuery = q.Encode()
 	}
 
-	req, err := http.NewRequest(http.MethodGet, getLogsURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, getLogsURL.String(), nil)
 	if err != nil {
 		return logs, cluster.ErrMakingRequest
 	}
@@ -156,7 +211,7 @@ func FindLatestJobName(c *cluster.Cluster, svcName string) (string, error) {
 		}
 
 		for jobName, info := range logMap.Jobs {
-			jobTime := extractJobTimestamp(info)
+			jobTime := JobTimestamp(info)
 			switch {
 			case latestName == "":
 				latestName = jobName
@@ -185,7 +240,8 @@ func FindLatestJobName(c *cluster.Cluster, svcName string) (string, error) {
 	return latestName, nil
 }
 
-func extractJobTimestamp(info *types.JobInfo) time.Time {
+// JobTimestamp returns the creation time of a job, falling back to its start or finish time when not available
+func JobTimestamp(info *types.JobInfo) time.Time {
 	if info == nil {
 		return time.Time{}
 	}
@@ -203,13 +259,18 @@ func extractJobTimestamp(info *types.JobInfo) time.Time {
 
 // RemoveLog removes the specified log (jobName) from a service in the cluster
 func RemoveLog(c *cluster.Cluster, svcName, jobName string) error {
+	return RemoveLogWithContext(context.Background(), c, svcName, jobName)
+}
+
+// RemoveLogWithContext removes the specified log (jobName) from a service in the cluster honouring context cancellation
+func RemoveLogWithContext(ctx context.Context, c *cluster.Cluster, svcName, jobName string) error {
 	removeLogURL, err := url.Parse(c.Endpoint)
 	if err != nil {
 		return cluster.ErrParsingEndpoint
 	}
 	removeLogURL.Path = path.Join(removeLogURL.Path, logsPath, svcName, jobName)
 
-	req, err := http.NewRequest(http.MethodDelete, removeLogURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, removeLogURL.String(), nil)
 	if err != nil {
 		return cluster.ErrMakingRequest
 	}
@@ -234,6 +295,11 @@ func RemoveLog(c *cluster.Cluster, svcName, jobName string) error {
 
 // RemoveLogs removes completed or all logs (jobs) from a service in the cluster
 func RemoveLogs(c *cluster.Cluster, svcName string, all bool) error {
+	return RemoveLogsWithContext(context.Background(), c, svcName, all)
+}
+
+// RemoveLogsWithContext removes completed or all logs (jobs) from a service in the cluster honouring context cancellation
+func RemoveLogsWithContext(ctx context.Context, c *cluster.Cluster, svcName string, all bool) error {
 	removeLogsURL, err := url.Parse(c.Endpoint)
 	if err != nil {
 		return cluster.ErrParsingEndpoint
@@ -246,7 +312,7 @@ func RemoveLogs(c *cluster.Cluster, svcName string, all bool) error {
 		removeLogsURL.RawQuery = q.Encode()
 	}
 
-	req, err := http.NewRequest(http.MethodDelete, removeLogsURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, removeLogsURL.String(), nil)
 	if err != nil {
 		return cluster.ErrMakingRequest
 	}
@@ -268,3 +334,148 @@ func RemoveLogs(c *cluster.Cluster, svcName string, all bool) error {
 
 	return nil
 }
+
+// GetJobInfo returns the info of a service's job, looking for it through all the pages of its logs
+func GetJobInfo(c *cluster.Cluster, svcName, jobName string) (*types.JobInfo, error) {
+	return GetJobInfoWithContext(context.Background(), c, svcName, jobName)
+}
+
+// GetJobInfoWithContext returns the info of a service's job honouring context cancellation
+func GetJobInfoWithContext(ctx context.Context, c *cluster.Cluster, svcName, jobName string) (*types.JobInfo, error) {
+	page := ""
+	for {
+		logMap, err := ListLogsWithContext(ctx, c, svcName, page)
+		if err != nil {
+			return nil, err
+		}
+
+		if info, ok := logMap.Jobs[jobName]; ok && info != nil {
+			return info, nil
+		}
+
+		if logMap.NextPage == "" {
+			return nil, ErrJobNotFound
+		}
+		page = logMap.NextPage
+	}
+}
+
+// IsJobFinished reports whether the job reached a terminal status
+func IsJobFinished(info *types.JobInfo) bool {
+	if info == nil {
+		return false
+	}
+	return strings.EqualFold(info.Status, JobSucceeded) || strings.EqualFold(info.Status, JobFailed)
+}
+
+// jobNotFoundGracePeriod is the time FollowLogs waits for a job that is not found in the cluster to appear,
+// as a job just submitted may not be listed yet
+var jobNotFoundGracePeriod = 10 * time.Second
+
+// jobLocator gets the info of a service's job remembering the page of the logs where it was found,
+// so polling the job doesn't go through all the pages of the logs every time
+type jobLocator struct {
+	c       *cluster.Cluster
+	svcName string
+	jobName string
+	page    string
+}
+
+// info returns the info of the job, checking first the page where it was last found
+func (l *jobLocator) info(ctx context.Context) (*types.JobInfo, error) {
+	if l.page != "" {
+		logMap, err := ListLogsWithContext(ctx, l.c, l.svcName, l.page)
+		if err == nil {
+			if info, ok := logMap.Jobs[l.jobName]; ok && info != nil {
+				return info, nil
+			}
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// The page may have expired or the job moved to another one, look for it from the beginning
+	}
+
+	page := ""
+	for {
+		logMap, err := ListLogsWithContext(ctx, l.c, l.svcName, page)
+		if err != nil {
+			return nil, err
+		}
+
+		if info, ok := logMap.Jobs[l.jobName]; ok && info != nil {
+			l.page = page
+			return info, nil
+		}
+
+		if logMap.NextPage == "" {
+			return nil, ErrJobNotFound
+		}
+		page = logMap.NextPage
+	}
+}
+
+// FollowLogs polls the logs of a service's job every interval, writing only the new lines to w,
+// until the job reaches a terminal status. The final job info is returned, or ErrJobNotFound if the job
+// is neither listed nor has logs for longer than a short grace period
+func FollowLogs(ctx context.Context, c *cluster.Cluster, svcName, jobName string, timestamps bool, interval time.Duration, w io.Writer) (*types.JobInfo, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	locator := &jobLocator{c: c, svcName: svcName, jobName: jobName}
+	lastSeen := time.Now()
+	printed := 0
+	for {
+		// Get the status before the logs, so the last logs are complete once the job has finished
+		info, err := locator.info(ctx)
+		if err != nil && !errors.Is(err, ErrJobNotFound) {
+			return nil, contextError(ctx, err)
+		}
+		finished := IsJobFinished(info)
+
+		logs, err := GetLogsWithContext(ctx, c, svcName, jobName, timestamps)
+		if info != nil || err == nil {
+			lastSeen = time.Now()
+		}
+		// The logs are not available until the job starts
+		if errors.Is(err, cluster.ErrNotFound) && !finished {
+			if info == nil && time.Since(lastSeen) >= jobNotFoundGracePeriod {
+				return nil, ErrJobNotFound
+			}
+			logs, err = "", nil
+		}
+		if err != nil {
+			return info, contextError(ctx, err)
+		}
+
+		// The logs can only be shorter if they were truncated in the cluster
+		if printed > len(logs) {
+			printed = len(logs)
+		}
+
+		// Keep incomplete lines until they are finished, unless the job is done
+		end := len(logs)
+		if !finished {
+			end = strings.LastIndex(logs, "\n") + 1
+		}
+		if end > printed {
+			if _, err := io.WriteString(w, logs[printed:end]); err != nil {
+				return info, err
+			}
+			printed = end
+		}
+
+		if finished {
+			if printed > 0 && !strings.HasSuffix(logs[:printed], "\n") {
+				fmt.Fprintln(w)
+			}
+			return info, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return info, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+)
+
+func setJobNotFoundGracePeriod(t *testing.T, grace time.Duration) {
+	t.Helper()
+	previous := jobNotFoundGracePeriod
+	jobNotFoundGracePeriod = grace
+	t.Cleanup(func() { jobNotFoundGracePeriod = previous })
+}
+
+func TestFollowLogsMissingJob(t *testing.T) {
+	setJobNotFoundGracePeriod(t, 20*time.Millisecond)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/system/logs/demo" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"jobs":{"other":{"status":"Running"}}}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var out bytes.Buffer
+	_, err := FollowLogs(ctx, &cluster.Cluster{Endpoint: server.URL}, "demo", "missing", false, time.Millisecond, &out)
+	if !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+}
+
+func TestFollowLogsWaitsForNewJob(t *testing.T) {
+	setJobNotFoundGracePeriod(t, 5*time.Second)
+
+	var mu sync.Mutex
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/demo":
+			polls++
+			w.Header().Set("Content-Type", "application/json")
+			if polls < 3 {
+				fmt.Fprint(w, `{"jobs":{}}`)
+				return
+			}
+			fmt.Fprint(w, `{"jobs":{"job-1":{"status":"Succeeded"}}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/demo/job-1" && polls >= 3:
+			fmt.Fprint(w, "done\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	info, err := FollowLogs(context.Background(), &cluster.Cluster{Endpoint: server.URL}, "demo", "job-1", false, time.Millisecond, &out)
+	if err != nil {
+		t.Fatalf("FollowLogs returned error: %v", err)
+	}
+	if info.Status != JobSucceeded || out.String() != "done\n" {
+		t.Fatalf("unexpected result %q with status %s", out.String(), info.Status)
+	}
+}
+
+func TestFollowLogsRemembersJobPage(t *testing.T) {
+	var mu sync.Mutex
+	firstPageRequests := 0
+	logRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/demo":
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("page") == "" {
+				firstPageRequests++
+				fmt.Fprint(w, `{"jobs":{"other":{"status":"Succeeded"}},"next_page":"p2"}`)
+				return
+			}
+			status := JobRunning
+			if logRequests >= 3 {
+				status = JobSucceeded
+			}
+			fmt.Fprintf(w, `{"jobs":{"job-1":{"status":%q}}}`, status)
+		case r.Method == http.MethodGet && r.URL.Path == "/system/logs/demo/job-1":
+			logRequests++
+			fmt.Fprint(w, "line\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	if _, err := FollowLogs(context.Background(), &cluster.Cluster{Endpoint: server.URL}, "demo", "job-1", false, time.Millisecond, &out); err != nil {
+		t.Fatalf("FollowLogs returned error: %v", err)
+	}
+	if logRequests < 4 {
+		t.Fatalf("expected several polls, got %d", logRequests)
+	}
+	if firstPageRequests != 1 {
+		t.Fatalf("expected the pages to be walked once, got %d requests to the first page", firstPageRequests)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// JobSelector selects the jobs of a service to be removed. The jobs without timestamps are only
+// selected by status, as their age and their order are unknown
+type JobSelector struct {
+	// OlderThan only selects the jobs created before this duration ago, zero selects any job
+	OlderThan time.Duration
+	// Statuses only selects the jobs with one of these statuses, if empty only finished jobs are selected
+	Statuses []string
+	// KeepLast never selects the given number of most recent jobs matching the statuses
+	KeepLast int
+}
+
+// Select returns the names of the selected jobs, sorted from the oldest to the most recent
+func (s JobSelector) Select(jobs map[string]*types.JobInfo, now time.Time) []string {
+	byTime := s.OlderThan > 0 || s.KeepLast > 0
+	matching := []string{}
+	for name, info := range jobs {
+		if info == nil || !s.matchesStatus(info) {
+			continue
+		}
+		if byTime && JobTimestamp(info).IsZero() {
+			continue
+		}
+		matching = append(matching, name)
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		ti, tj := JobTimestamp(jobs[matching[i]]), JobTimestamp(jobs[matching[j]])
+		if ti.Equal(tj) {
+			return matching[i] < matching[j]
+		}
+		return ti.Before(tj)
+	})
+
+	if s.KeepLast > 0 {
+		if s.KeepLast >= len(matching) {
+			return []string{}
+		}
+		matching = matching[:len(matching)-s.KeepLast]
+	}
+
+	if s.OlderThan <= 0 {
+		return matching
+	}
+	limit := now.Add(-s.OlderThan)
+	selected := []string{}
+	for _, name := range matching {
+		if JobTimestamp(jobs[name]).Before(limit) {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+func (s JobSelector) matchesStatus(info *types.JobInfo) bool {
+	if len(s.Statuses) == 0 {
+		return IsJobFinished(info)
+	}
+	for _, status := range s.Statuses {
+		if strings.EqualFold(status, info.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveJobs removes the given jobs of a service along with their logs using at most workers concurrent requests.
+// The errors are returned by job name, the jobs not started when the context is done are reported with its error
+func RemoveJobs(ctx context.Context, c *cluster.Cluster, svcName string, jobNames []string, workers int) map[string]error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	var mu sync.Mutex
+	errs := map[string]error{}
+	pending := make(chan string)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for jobName := range pending {
+				err := ctx.Err()
+				if err == nil {
+					err = RemoveLogWithContext(ctx, c, svcName, jobName)
+				}
+				if err != nil {
+					mu.Lock()
+					errs[jobName] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, jobName := range jobNames {
+		pending <- jobName
+	}
+	close(pending)
+	wg.Wait()
+
+	return errs
+}
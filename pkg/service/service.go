@@ -19,6 +19,8 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -39,8 +41,18 @@ const servicesPath = "/system/services"
 const runPath = "/run"
 const jobPath = "/job"
 
+// FDLLabel is the label stamped on the services applied from an FDL file to track their origin
+const FDLLabel = "oscar-cli/fdl"
+
+const maxLabelValueLength = 63
+
+// fdlPathHashLength is the number of hexadecimal characters of the path hash used in the derived FDL identifiers
+const fdlPathHashLength = 8
+
 // FDL represents a Functions Definition Language file
 type FDL struct {
+	// ID identifies the services applied from the file to be able to prune them, see FDLIdentifier
+	ID        string `json:"id,omitempty"`
 	Functions struct {
 		Oscar []map[string]*types.Service `json:"oscar" binding:"required"`
 	} `json:"functions" binding:"required"`
@@ -50,6 +62,11 @@ type FDL struct {
 
 // ReadFDL reads the content of FDL file and returns a valid FDL struct with the scripts and StorageProviders embedded into the services
 func ReadFDL(path string) (fdl *FDL, err error) {
+	return ReadFDLWithValues(path, nil)
+}
+
+// ReadFDLWithValues reads an FDL file like ReadFDL, resolving its placeholders with RenderFDL when values are provided
+func ReadFDLWithValues(path string, values FDLValues) (fdl *FDL, err error) {
 	fdl = &FDL{}
 	// Read the file
 	content, err := os.ReadFile(path)
@@ -60,6 +77,14 @@ func ReadFDL(path string) (fdl *FDL, err error) {
 	safeContent := strings.Replace(string(content), "\r\n", "\n", -1)
 	content = []byte(safeContent)
 
+	// Resolve placeholders
+	if values != nil {
+		content, err = RenderFDL(content, values)
+		if err != nil {
+			return fdl, err
+		}
+	}
+
 	// Unmarshal the FDL
 	err = yaml.Unmarshal(content, fdl)
 	if err != nil {
@@ -91,15 +116,60 @@ func ReadFDL(path string) (fdl *FDL, err error) {
 	return fdl, nil
 }
 
+// FDLIdentifier returns the value used in the FDLLabel of the services defined in the given FDL file.
+// An explicit identifier (set in the "id" field of the FDL or with "--fdl-id") is kept when the file is renamed
+// or moved. Otherwise it is derived from the file name and a hash of its absolute path, so files with the same
+// name in different directories don't share it. When the service names are overridden the new name is appended
+// to avoid mixing them with the original deployment
+func FDLIdentifier(fdlPath string, id string, nameOverride string) string {
+	suffix := ""
+	id = strings.TrimSpace(id)
+	if id == "" {
+		base := filepath.Base(fdlPath)
+		id = strings.TrimSuffix(base, filepath.Ext(base))
+		absPath, err := filepath.Abs(fdlPath)
+		if err != nil {
+			absPath = fdlPath
+		}
+		sum := sha256.Sum256([]byte(absPath))
+		suffix = "-" + hex.EncodeToString(sum[:])[:fdlPathHashLength]
+	}
+	if trimmed := strings.TrimSpace(nameOverride); trimmed != "" {
+		id = id + "-" + trimmed
+	}
+
+	var builder strings.Builder
+	for _, r := range strings.ToLower(id) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('-')
+		}
+	}
+
+	id = builder.String()
+	if len(id) > maxLabelValueLength-len(suffix) {
+		id = id[:maxLabelValueLength-len(suffix)]
+	}
+	// Label values must begin and end with an alphanumeric character
+	return strings.Trim(strings.Trim(id, "-_.")+suffix, "-_.")
+}
+
 // GetService gets a service from a cluster
 func GetService(c *cluster.Cluster, name string) (svc *types.Service, err error) {
+	return GetServiceWithContext(context.Background(), c, name)
+}
+
+// GetServiceWithContext gets a service from a cluster honouring context cancellation
+func GetServiceWithContext(ctx context.Context, c *cluster.Cluster, name string) (svc *types.Service, err error) {
 	getServiceURL, err := url.Parse(c.Endpoint)
 	if err != nil {
 		return svc, cluster.ErrParsingEndpoint
 	}
 	getServiceURL.Path = path.Join(getServiceURL.Path, servicesPath, name)
 
-	req, err := http.NewRequest(http.MethodGet, getServiceURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, getServiceURL.String(), nil)
 	if err != nil {
 		return svc, cluster.ErrMakingRequest
 	}
@@ -176,13 +246,18 @@ func ListServicesWithContext(ctx context.Context, c *cluster.Cluster) (svcList [
 
 // RemoveService removes a service from a cluster
 func RemoveService(c *cluster.Cluster, name string) error {
+	return RemoveServiceWithContext(context.Background(), c, name)
+}
+
+// RemoveServiceWithContext removes a service from a cluster honouring context cancellation
+func RemoveServiceWithContext(ctx context.Context, c *cluster.Cluster, name string) error {
 	removeServiceURL, err := url.Parse(c.Endpoint)
 	if err != nil {
 		return cluster.ErrParsingEndpoint
 	}
 	removeServiceURL.Path = path.Join(removeServiceURL.Path, servicesPath, name)
 
-	req, err := http.NewRequest(http.MethodDelete, removeServiceURL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, removeServiceURL.String(), nil)
 	if err != nil {
 		return cluster.ErrMakingRequest
 	}
@@ -207,6 +282,11 @@ func RemoveService(c *cluster.Cluster, name string) error {
 
 // ApplyService creates or edit a service in the specified cluster
 func ApplyService(svc *types.Service, c *cluster.Cluster, method string) error {
+	return ApplyServiceWithContext(context.Background(), svc, c, method)
+}
+
+// ApplyServiceWithContext creates or edit a service in the specified cluster honouring context cancellation
+func ApplyServiceWithContext(ctx context.Context, svc *types.Service, c *cluster.Cluster, method string) error {
 	// Check valid methods (only POST and PUT are allowed)
 	if method != http.MethodPost && method != http.MethodPut {
 		return errors.New("invalid method")
@@ -225,7 +305,7 @@ func ApplyService(svc *types.Service, c *cluster.Cluster, method string) error {
 	reqBody := bytes.NewBuffer(svcBytes)
 
 	// Make the request
-	req, err := http.NewRequest(method, applyServiceURL.String(), reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, applyServiceURL.String(), reqBody)
 	if err != nil {
 		return cluster.ErrMakingRequest
 	}
@@ -254,17 +334,34 @@ func ApplyService(svc *types.Service, c *cluster.Cluster, method string) error {
 	return nil
 }
 
+// RunOptions configures a synchronous invocation
+type RunOptions struct {
+	// Token and Endpoint invoke the service of a non registered cluster
+	Token    string
+	Endpoint string
+	// ContentType of the input, not set if empty
+	ContentType string
+	// Headers are added to the request
+	Headers http.Header
+	// Context of the request, context.Background() if nil
+	Context context.Context
+}
+
 // RunService invokes a service synchronously (a Serverless backend in the cluster is required)
 func RunService(c *cluster.Cluster, name string, token string, endpoint string, input io.Reader) (responseBody io.ReadCloser, err error) {
+	return RunServiceWithOptions(c, name, input, RunOptions{Token: token, Endpoint: endpoint})
+}
+
+// RunServiceWithOptions invokes a service synchronously sending the input untouched, so it must be base64 encoded
+// unless the service handles raw requests. The response body is returned without being buffered
+func RunServiceWithOptions(c *cluster.Cluster, name string, input io.Reader, opts RunOptions) (responseBody io.ReadCloser, err error) {
 	client := http.DefaultClient
-	if token == "" {
+	if opts.Token == "" {
 		client, _ = c.GetClientSafe()
-	} else {
-		client = http.DefaultClient
 	}
 	var runServiceURL *url.URL
-	if endpoint != "" {
-		runServiceURL, err = url.Parse(endpoint)
+	if opts.Endpoint != "" {
+		runServiceURL, err = url.Parse(opts.Endpoint)
 	} else {
 		runServiceURL, err = url.Parse(c.Endpoint)
 	}
@@ -274,13 +371,25 @@ func RunService(c *cluster.Cluster, name string, token string, endpoint string,
 	}
 	runServiceURL.Path = path.Join(runServiceURL.Path, runPath, name)
 	// Make the request
-	req, err := http.NewRequest(http.MethodPost, runServiceURL.String(), input)
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
 	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, runServiceURL.String(), input)
 	if err != nil {
 		return nil, cluster.ErrMakingRequest
 	}
+	for key, values := range opts.Headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if opts.ContentType != "" {
+		req.Header.Set("Content-Type", opts.ContentType)
+	}
+	if opts.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.Token)
+	}
 
 	res, err := client.Do(req)
 
@@ -289,6 +398,7 @@ func RunService(c *cluster.Cluster, name string, token string, endpoint string,
 	}
 
 	if err := cluster.CheckStatusCode(res); err != nil {
+		res.Body.Close()
 		return nil, err
 	}
 
@@ -297,6 +407,11 @@ func RunService(c *cluster.Cluster, name string, token string, endpoint string,
 
 // JobService invokes a service asynchronously
 func JobService(c *cluster.Cluster, name string, token string, endpoint string, input io.Reader) (responseBody io.ReadCloser, err error) {
+	return JobServiceWithContext(context.Background(), c, name, token, endpoint, input)
+}
+
+// JobServiceWithContext invokes a service asynchronously honouring context cancellation
+func JobServiceWithContext(ctx context.Context, c *cluster.Cluster, name string, token string, endpoint string, input io.Reader) (responseBody io.ReadCloser, err error) {
 	client := http.DefaultClient
 	if token == "" {
 		client, _ = c.GetClientSafe()
@@ -316,7 +431,7 @@ func JobService(c *cluster.Cluster, name string, token string, endpoint string,
 	}
 	jobServiceURL.Path = path.Join(jobServiceURL.Path, jobPath, name)
 	// Make the request
-	req, err := http.NewRequest(http.MethodPost, jobServiceURL.String(), input)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, jobServiceURL.String(), input)
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
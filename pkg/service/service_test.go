@@ -81,6 +81,45 @@ functions:
 	}
 }
 
+func TestFDLIdentifier(t *testing.T) {
+	cases := []struct {
+		path     string
+		id       string
+		override string
+		expected string
+	}{
+		{"/tmp/example-workflow.yaml", "", "", "example-workflow-"},
+		{"My Workflow.yml", "", "", "my-workflow-"},
+		{"workflow.yaml", "", "Staging", "workflow-staging-"},
+		{"_workflow_.yaml", "", "", "workflow-"},
+		{"/tmp/workflow.yaml", "Image Pipeline", "", "image-pipeline"},
+		{"/tmp/workflow.yaml", "pipeline", "staging", "pipeline-staging"},
+	}
+
+	for _, tc := range cases {
+		got := FDLIdentifier(tc.path, tc.id, tc.override)
+		if tc.id != "" {
+			if got != tc.expected {
+				t.Fatalf("FDLIdentifier(%q, %q, %q) = %q, want %q", tc.path, tc.id, tc.override, got, tc.expected)
+			}
+			continue
+		}
+		if !strings.HasPrefix(got, tc.expected) || len(got) != len(tc.expected)+fdlPathHashLength {
+			t.Fatalf("FDLIdentifier(%q, %q, %q) = %q, want %q followed by the path hash", tc.path, tc.id, tc.override, got, tc.expected)
+		}
+	}
+
+	if FDLIdentifier("/a/fdl.yaml", "", "") == FDLIdentifier("/b/fdl.yaml", "", "") {
+		t.Fatalf("expected FDL files with the same name in different directories to have different identifiers")
+	}
+	if FDLIdentifier("/a/fdl.yaml", "", "") != FDLIdentifier("/a/../a/fdl.yaml", "", "") {
+		t.Fatalf("expected the identifier to depend on the absolute path of the file")
+	}
+	if long := FDLIdentifier("/tmp/"+strings.Repeat("a", 100)+".yaml", "", ""); len(long) > maxLabelValueLength {
+		t.Fatalf("expected identifier of at most %d characters, got %q", maxLabelValueLength, long)
+	}
+}
+
 func TestApplyService(t *testing.T) {
 	const (
 		serviceName = "demo"
@@ -0,0 +1,126 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// JobStats summarizes the jobs of a service
+type JobStats struct {
+	Total    int            `json:"total"`
+	Statuses map[string]int `json:"statuses"`
+	// FailureRate is the ratio of failed jobs over the finished ones
+	FailureRate        float64          `json:"failure_rate"`
+	DurationP50Seconds float64          `json:"duration_p50_seconds"`
+	DurationP95Seconds float64          `json:"duration_p95_seconds"`
+	Periods            []JobStatsPeriod `json:"periods"`
+}
+
+// JobStatsPeriod summarizes the jobs created in a period of time
+type JobStatsPeriod struct {
+	Start       time.Time `json:"start"`
+	Jobs        int       `json:"jobs"`
+	Finished    int       `json:"finished"`
+	Failed      int       `json:"failed"`
+	FailureRate float64   `json:"failure_rate"`
+}
+
+// ComputeJobStats returns the counts per status, the median and 95th percentile durations of the finished jobs
+// and the failure rate, both in total and grouped by periods of the given length
+func ComputeJobStats(jobs map[string]*types.JobInfo, period time.Duration) *JobStats {
+	stats := &JobStats{
+		Statuses: map[string]int{},
+		Periods:  []JobStatsPeriod{},
+	}
+
+	durations := []time.Duration{}
+	periods := map[time.Time]*JobStatsPeriod{}
+	finished, failed := 0, 0
+	for _, info := range jobs {
+		if info == nil {
+			continue
+		}
+		stats.Total++
+		stats.Statuses[info.Status]++
+
+		isFailed := strings.EqualFold(info.Status, JobFailed)
+		if IsJobFinished(info) {
+			finished++
+			if isFailed {
+				failed++
+			}
+		}
+
+		if info.StartTime != nil && info.FinishTime != nil {
+			durations = append(durations, info.FinishTime.Sub(info.StartTime.Time))
+		}
+
+		jobTime := JobTimestamp(info)
+		if period <= 0 || jobTime.IsZero() {
+			continue
+		}
+		start := jobTime.UTC().Truncate(period)
+		p, ok := periods[start]
+		if !ok {
+			p = &JobStatsPeriod{Start: start}
+			periods[start] = p
+		}
+		p.Jobs++
+		if IsJobFinished(info) {
+			p.Finished++
+		}
+		if isFailed {
+			p.Failed++
+		}
+	}
+
+	if finished > 0 {
+		stats.FailureRate = float64(failed) / float64(finished)
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.DurationP50Seconds = Percentile(durations, 50).Seconds()
+	stats.DurationP95Seconds = Percentile(durations, 95).Seconds()
+
+	for _, p := range periods {
+		if p.Finished > 0 {
+			p.FailureRate = float64(p.Failed) / float64(p.Finished)
+		}
+		stats.Periods = append(stats.Periods, *p)
+	}
+	sort.Slice(stats.Periods, func(i, j int) bool { return stats.Periods[i].Start.Before(stats.Periods[j].Start) })
+
+	return stats
+}
+
+// Percentile returns the p-th percentile of the sorted durations using the nearest-rank method
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grycap/oscar/v3/pkg/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testJob(status string, created time.Time, duration time.Duration) *types.JobInfo {
+	info := &types.JobInfo{
+		Status:       status,
+		CreationTime: &metav1.Time{Time: created},
+	}
+	if duration > 0 {
+		info.StartTime = &metav1.Time{Time: created}
+		info.FinishTime = &metav1.Time{Time: created.Add(duration)}
+	}
+	return info
+}
+
+func TestComputeJobStats(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	jobs := map[string]*types.JobInfo{
+		"job-1": testJob(JobSucceeded, day.Add(1*time.Hour), 10*time.Second),
+		"job-2": testJob(JobSucceeded, day.Add(2*time.Hour), 20*time.Second),
+		"job-3": testJob(JobFailed, day.Add(3*time.Hour), 30*time.Second),
+		"job-4": testJob(JobSucceeded, day.Add(25*time.Hour), 40*time.Second),
+		"job-5": testJob(JobRunning, day.Add(26*time.Hour), 0),
+	}
+
+	stats := ComputeJobStats(jobs, 24*time.Hour)
+
+	if stats.Total != 5 {
+		t.Fatalf("expected 5 jobs, got %d", stats.Total)
+	}
+	if stats.Statuses[JobSucceeded] != 3 || stats.Statuses[JobFailed] != 1 || stats.Statuses[JobRunning] != 1 {
+		t.Fatalf("unexpected status counts %v", stats.Statuses)
+	}
+	if stats.FailureRate != 0.25 {
+		t.Fatalf("expected a failure rate of 0.25 over the finished jobs, got %v", stats.FailureRate)
+	}
+	if stats.DurationP50Seconds != 20 || stats.DurationP95Seconds != 40 {
+		t.Fatalf("unexpected percentiles p50=%v p95=%v", stats.DurationP50Seconds, stats.DurationP95Seconds)
+	}
+
+	if len(stats.Periods) != 2 {
+		t.Fatalf("expected 2 periods, got %+v", stats.Periods)
+	}
+	first, second := stats.Periods[0], stats.Periods[1]
+	if !first.Start.Equal(day) || first.Jobs != 3 || first.Failed != 1 {
+		t.Fatalf("unexpected first period %+v", first)
+	}
+	if second.Jobs != 2 || second.Finished != 1 || second.FailureRate != 0 {
+		t.Fatalf("unexpected second period %+v", second)
+	}
+}
+
+func TestComputeJobStatsEmpty(t *testing.T) {
+	stats := ComputeJobStats(map[string]*types.JobInfo{}, time.Hour)
+	if stats.Total != 0 || stats.FailureRate != 0 || stats.DurationP50Seconds != 0 || len(stats.Periods) != 0 {
+		t.Fatalf("unexpected stats for no jobs %+v", stats)
+	}
+}
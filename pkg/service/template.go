@@ -0,0 +1,188 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/goccy/go-yaml"
+)
+
+// variablePattern matches "$${" escapes and "${NAME}" or "${NAME:-default}" placeholders
+var variablePattern = regexp.MustCompile(`\$\$\{|\$\{([^}:]+)(?::-([^}]*))?\}`)
+
+// FDLValues contains the values used to resolve the placeholders of an FDL file.
+// Nested values are accessed through dotted keys (e.g. "image.tag")
+type FDLValues map[string]interface{}
+
+// Set adds a KEY=VALUE assignment to the values, creating the nested maps of dotted keys
+func (values FDLValues) Set(assignment string) error {
+	key, value, found := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return fmt.Errorf("invalid value \"%s\", it must have the form KEY=VALUE", assignment)
+	}
+
+	parts := strings.Split(key, ".")
+	current := map[string]interface{}(values)
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+
+	return nil
+}
+
+// MergeFile merges the values defined in a YAML file, overriding the existing ones
+func (values FDLValues) MergeFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read the values file \"%s\", please check the path", path)
+	}
+
+	fileValues := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &fileValues); err != nil {
+		return fmt.Errorf("the values file \"%s\" is not valid: %v", path, err)
+	}
+
+	mergeValues(values, fileValues)
+	return nil
+}
+
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := toStringMap(value)
+		dstMap, dstIsMap := toStringMap(dst[key])
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			dst[key] = dstMap
+			continue
+		}
+		if srcIsMap {
+			nested := map[string]interface{}{}
+			mergeValues(nested, srcMap)
+			dst[key] = nested
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case FDLValues:
+		return v, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			out[fmt.Sprint(key)] = child
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// lookup returns the value of a dotted key
+func (values FDLValues) lookup(key string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(values)
+	for _, part := range strings.Split(key, ".") {
+		m, ok := toStringMap(current)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// RenderFDL resolves the placeholders of an FDL file. Go template actions (e.g. "{{ .Values.image }}") are
+// executed first, then "${NAME}" variables are replaced by the value with that key or, if not set, by the
+// environment variable with that name. "${NAME:-default}" sets a default value and "$${" escapes a literal "${"
+func RenderFDL(content []byte, values FDLValues) ([]byte, error) {
+	if values == nil {
+		values = FDLValues{}
+	}
+
+	if bytes.Contains(content, []byte("{{")) {
+		tmpl, err := template.New("fdl").Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("the FDL template is not valid: %v", err)
+		}
+		data := map[string]interface{}{
+			"Values": map[string]interface{}(values),
+			"Env":    environMap(),
+		}
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("unable to render the FDL template: %v", err)
+		}
+		content = rendered.Bytes()
+	}
+
+	var renderErr error
+	content = variablePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		if string(match) == "$${" {
+			return []byte("${")
+		}
+		groups := variablePattern.FindSubmatch(match)
+		name := strings.TrimSpace(string(groups[1]))
+
+		if value, ok := values.lookup(name); ok {
+			return []byte(fmt.Sprint(value))
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return []byte(value)
+		}
+		if bytes.Contains(match, []byte(":-")) {
+			return groups[2]
+		}
+		if renderErr == nil {
+			renderErr = fmt.Errorf("the variable \"%s\" used in the FDL file is not defined, please set it with \"--set\", \"--values\" or as an environment variable", name)
+		}
+		return match
+	})
+	if renderErr != nil {
+		return nil, renderErr
+	}
+
+	return content, nil
+}
+
+func environMap() map[string]string {
+	env := map[string]string{}
+	for _, entry := range os.Environ() {
+		if key, value, found := strings.Cut(entry, "="); found {
+			env[key] = value
+		}
+	}
+	return env
+}
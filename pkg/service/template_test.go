@@ -0,0 +1,82 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderFDL(t *testing.T) {
+	t.Setenv("OSCAR_TEST_BUCKET", "env-bucket")
+
+	values := FDLValues{}
+	for _, assignment := range []string{"image.tag=1.2", "memory=2Gi"} {
+		if err := values.Set(assignment); err != nil {
+			t.Fatalf("Set(%q) returned error: %v", assignment, err)
+		}
+	}
+
+	content := []byte(`image: demo:${image.tag}
+memory: {{ .Values.memory }}
+path: ${OSCAR_TEST_BUCKET}/in
+cpu: ${CPU:-0.5}
+literal: $${HOME}
+`)
+
+	rendered, err := RenderFDL(content, values)
+	if err != nil {
+		t.Fatalf("RenderFDL returned error: %v", err)
+	}
+
+	expected := `image: demo:1.2
+memory: 2Gi
+path: env-bucket/in
+cpu: 0.5
+literal: ${HOME}
+`
+	if string(rendered) != expected {
+		t.Fatalf("unexpected rendered FDL:\n%s\nwant:\n%s", rendered, expected)
+	}
+}
+
+func TestRenderFDLUndefinedVariable(t *testing.T) {
+	_, err := RenderFDL([]byte("image: ${OSCAR_TEST_UNDEFINED_IMAGE}\n"), FDLValues{})
+	if err == nil || !strings.Contains(err.Error(), "OSCAR_TEST_UNDEFINED_IMAGE") {
+		t.Fatalf("expected undefined variable error, got %v", err)
+	}
+
+	_, err = RenderFDL([]byte("image: {{ .Values.image }}\n"), FDLValues{})
+	if err == nil {
+		t.Fatalf("expected error for missing template value")
+	}
+}
+
+func TestFDLValuesMergeFile(t *testing.T) {
+	valuesPath := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesPath, []byte("image:\n  name: demo\n  tag: latest\nreplicas: 2\n"), 0o600); err != nil {
+		t.Fatalf("writing values: %v", err)
+	}
+
+	values := FDLValues{}
+	if err := values.MergeFile(valuesPath); err != nil {
+		t.Fatalf("MergeFile returned error: %v", err)
+	}
+	if err := values.Set("image.tag=1.0"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+
+	rendered, err := RenderFDL([]byte("{{ .Values.image.name }}:${image.tag} x${replicas}"), values)
+	if err != nil {
+		t.Fatalf("RenderFDL returned error: %v", err)
+	}
+	if string(rendered) != "demo:1.0 x2" {
+		t.Fatalf("unexpected rendered content %q", rendered)
+	}
+}
+
+func TestFDLValuesSetInvalid(t *testing.T) {
+	if err := (FDLValues{}).Set("novalue"); err == nil {
+		t.Fatalf("expected error for assignment without value")
+	}
+}
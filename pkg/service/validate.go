@@ -0,0 +1,401 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/grycap/oscar/v3/pkg/types"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Severities of the issues found when validating an FDL file
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// yamlErrorPosition matches the "[line:column]" prefix of the YAML parser errors
+var yamlErrorPosition = regexp.MustCompile(`^\[(\d+):(\d+)\]\s*`)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// ValidationIssue is a problem found when validating an FDL file. Path is the dotted path
+// of the affected element (e.g. "functions.oscar[0].my-cluster.memory")
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ValidationOptions configures the checks made by ValidateFDL
+type ValidationOptions struct {
+	// Values used to resolve the placeholders of the FDL file, nil to read it literally
+	Values FDLValues
+	// CheckCluster returns an error if a cluster identifier cannot be used, nil to skip the check
+	CheckCluster func(clusterID string) error
+}
+
+type fdlValidator struct {
+	path      string
+	positions map[string]*token.Position
+	issues    []ValidationIssue
+}
+
+// ValidateFDL checks an FDL file without contacting any cluster and returns the issues found sorted by position.
+// An error is only returned when the file cannot be read
+func ValidateFDL(path string, opts ValidationOptions) ([]ValidationIssue, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("cannot read the file, please check the path")
+	}
+	// Change \r\n line ending to \n to avoid YAML unmarshal errors
+	content = []byte(strings.Replace(string(content), "\r\n", "\n", -1))
+
+	if opts.Values != nil {
+		content, err = RenderFDL(content, opts.Values)
+		if err != nil {
+			return []ValidationIssue{{Severity: SeverityError, Message: err.Error()}}, nil
+		}
+	}
+
+	v := &fdlValidator{path: path, positions: map[string]*token.Position{}, issues: []ValidationIssue{}}
+
+	file, err := parser.ParseBytes(content, 0)
+	if err != nil {
+		return []ValidationIssue{yamlErrorIssue(err)}, nil
+	}
+	if len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		return []ValidationIssue{{Severity: SeverityError, Message: "the FDL file is empty"}}, nil
+	}
+	v.walk(file.Docs[0].Body, reflect.TypeOf(FDL{}), "")
+
+	fdl := &FDL{}
+	if err := yaml.Unmarshal(content, fdl); err != nil {
+		v.issues = append(v.issues, yamlErrorIssue(err))
+		return v.sorted(), nil
+	}
+
+	v.checkServices(fdl, opts)
+
+	return v.sorted(), nil
+}
+
+func yamlErrorIssue(err error) ValidationIssue {
+	msg := strings.TrimSpace(yaml.FormatError(err, false, false))
+	issue := ValidationIssue{Severity: SeverityError, Message: msg}
+	if match := yamlErrorPosition.FindStringSubmatch(msg); match != nil {
+		issue.Line, _ = strconv.Atoi(match[1])
+		issue.Column, _ = strconv.Atoi(match[2])
+		issue.Message = msg[len(match[0]):]
+	}
+	return issue
+}
+
+// walk records the position of every key and reports the ones not matching a field of the expected type
+func (v *fdlValidator) walk(node ast.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			v.walkMappingValue(value, t, path)
+		}
+	case *ast.MappingValueNode:
+		v.walkMappingValue(n, t, path)
+	case *ast.SequenceNode:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		for i, item := range n.Values {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if item != nil {
+				v.positions[itemPath] = item.GetToken().Position
+			}
+			v.walk(item, t.Elem(), itemPath)
+		}
+	}
+}
+
+func (v *fdlValidator) walkMappingValue(node *ast.MappingValueNode, t reflect.Type, path string) {
+	if node.Key == nil {
+		return
+	}
+	key := node.Key.GetToken().Value
+	if key == "<<" {
+		// Merge keys are resolved by the decoder
+		return
+	}
+	keyPath := key
+	if path != "" {
+		keyPath = path + "." + key
+	}
+	v.positions[keyPath] = node.Key.GetToken().Position
+
+	switch t.Kind() {
+	case reflect.Struct:
+		field, found := yamlField(t, key)
+		if !found {
+			v.add(SeverityError, keyPath, fmt.Sprintf("unknown field \"%s\"", key))
+			return
+		}
+		v.walk(node.Value, field.Type, keyPath)
+	case reflect.Map:
+		v.walk(node.Value, t.Elem(), keyPath)
+	}
+}
+
+// yamlField returns the field of a struct decoded from the given key, following the naming rules of the YAML decoder
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "" {
+			tag = field.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		options := strings.Split(tag, ",")
+		for _, option := range options[1:] {
+			if option != "inline" {
+				continue
+			}
+			inlined := field.Type
+			for inlined.Kind() == reflect.Ptr {
+				inlined = inlined.Elem()
+			}
+			if inlined.Kind() == reflect.Struct {
+				if f, found := yamlField(inlined, key); found {
+					return f, true
+				}
+			}
+		}
+		name := options[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if name == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func (v *fdlValidator) checkServices(fdl *FDL, opts ValidationOptions) {
+	if len(fdl.Functions.Oscar) == 0 {
+		v.add(SeverityError, "functions.oscar", "there are no services defined in \"functions.oscar\"")
+		return
+	}
+
+	declared := declaredStorageProviders(fdl.StorageProviders)
+	defined := map[string]bool{}
+	for i, element := range fdl.Functions.Oscar {
+		clusterIDs := make([]string, 0, len(element))
+		for clusterID := range element {
+			clusterIDs = append(clusterIDs, clusterID)
+		}
+		sort.Strings(clusterIDs)
+
+		for _, clusterID := range clusterIDs {
+			svc := element[clusterID]
+			svcPath := fmt.Sprintf("functions.oscar[%d].%s", i, clusterID)
+			if svc == nil {
+				v.add(SeverityError, svcPath, "the service definition is empty")
+				continue
+			}
+
+			if opts.CheckCluster != nil {
+				if err := opts.CheckCluster(clusterID); err != nil {
+					v.add(SeverityError, svcPath, err.Error())
+				}
+			}
+
+			if strings.TrimSpace(svc.Name) == "" {
+				v.add(SeverityError, svcPath, "the service name is required")
+			} else if defined[clusterID+"/"+svc.Name] {
+				v.add(SeverityError, svcPath+".name", fmt.Sprintf("the service \"%s\" is defined more than once for cluster \"%s\"", svc.Name, clusterID))
+			} else {
+				defined[clusterID+"/"+svc.Name] = true
+			}
+			if strings.TrimSpace(svc.Image) == "" {
+				v.add(SeverityError, svcPath, "the service image is required")
+			}
+
+			v.checkQuantity(svcPath+".memory", "memory", svc.Memory)
+			v.checkQuantity(svcPath+".cpu", "cpu", svc.CPU)
+			v.checkScript(svcPath, svc)
+			v.checkStorage(svcPath+".input", svc.Input, declared)
+			v.checkStorage(svcPath+".output", svc.Output, declared)
+			v.checkRecursiveOutputs(svcPath, svc)
+		}
+	}
+}
+
+func (v *fdlValidator) checkQuantity(path, field, value string) {
+	if value == "" {
+		return
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		v.add(SeverityError, path, fmt.Sprintf("invalid %s quantity \"%s\"", field, value))
+		return
+	}
+	if quantity.Sign() <= 0 {
+		v.add(SeverityError, path, fmt.Sprintf("the %s quantity \"%s\" must be greater than zero", field, value))
+	}
+}
+
+func (v *fdlValidator) checkScript(svcPath string, svc *types.Service) {
+	if strings.TrimSpace(svc.Script) == "" {
+		v.add(SeverityError, svcPath, "the service script is required")
+		return
+	}
+	scriptPath := getScriptPath(svc.Script, v.path)
+	info, err := os.Stat(scriptPath)
+	if err != nil || info.IsDir() {
+		v.add(SeverityError, svcPath+".script", fmt.Sprintf("cannot find the script \"%s\"", scriptPath))
+	}
+}
+
+func (v *fdlValidator) checkStorage(path string, configs []types.StorageIOConfig, declared map[string]bool) {
+	for i, config := range configs {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if strings.TrimSpace(config.Path) == "" {
+			v.add(SeverityError, itemPath, "the storage path is required")
+		}
+
+		provider := strings.TrimSpace(config.Provider)
+		if provider == "" || isDefaultMinIOProvider(provider) {
+			continue
+		}
+		parts := strings.SplitN(provider, types.ProviderSeparator, 2)
+		switch parts[0] {
+		case types.MinIOName, types.S3Name, types.OnedataName, types.WebDavName:
+		default:
+			v.add(SeverityError, itemPath+".storage_provider", fmt.Sprintf("unknown storage provider type \"%s\"", parts[0]))
+			continue
+		}
+		if len(parts) != 2 || parts[1] == "" {
+			v.add(SeverityError, itemPath+".storage_provider", fmt.Sprintf("the storage provider \"%s\" is not valid, it must have the form <PROVIDER_NAME>.<PROVIDER_IDENTIFIER>", provider))
+			continue
+		}
+		if !declared[provider] {
+			v.add(SeverityError, itemPath+".storage_provider", fmt.Sprintf("the storage provider \"%s\" is not declared in \"storage_providers\"", provider))
+		}
+	}
+}
+
+// checkRecursiveOutputs warns about outputs uploaded to an input path of the same service, as each result would trigger a new invocation
+func (v *fdlValidator) checkRecursiveOutputs(svcPath string, svc *types.Service) {
+	inputs := map[string]bool{}
+	for _, input := range svc.Input {
+		inputs[storageKey(input)] = true
+	}
+	for i, output := range svc.Output {
+		if strings.TrimSpace(output.Path) != "" && inputs[storageKey(output)] {
+			v.add(SeverityWarning, fmt.Sprintf("%s.output[%d]", svcPath, i), fmt.Sprintf("the output path \"%s\" is also an input of the service, each result will trigger a new invocation", output.Path))
+		}
+	}
+}
+
+func storageKey(config types.StorageIOConfig) string {
+	provider := strings.TrimSpace(config.Provider)
+	if provider == "" || isDefaultMinIOProvider(provider) {
+		provider = types.MinIOName + types.ProviderSeparator + "default"
+	}
+	return provider + ":" + strings.Trim(strings.TrimSpace(config.Path), "/")
+}
+
+func isDefaultMinIOProvider(provider string) bool {
+	return provider == types.MinIOName || provider == types.MinIOName+types.ProviderSeparator+"default"
+}
+
+// declaredStorageProviders returns the identifiers ("<PROVIDER_NAME>.<PROVIDER_IDENTIFIER>") of the providers defined in the FDL
+func declaredStorageProviders(providers *types.StorageProviders) map[string]bool {
+	declared := map[string]bool{}
+	if providers == nil {
+		return declared
+	}
+	content, err := json.Marshal(providers)
+	if err != nil {
+		return declared
+	}
+	byType := map[string]map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &byType); err != nil {
+		return declared
+	}
+	for providerType, ids := range byType {
+		for id := range ids {
+			declared[providerType+types.ProviderSeparator+id] = true
+		}
+	}
+	return declared
+}
+
+// add records an issue at the position of the given path or, if not found, of its closest parent
+func (v *fdlValidator) add(severity, path, message string) {
+	issue := ValidationIssue{Severity: severity, Path: path, Message: message}
+	for p := path; p != ""; p = parentPath(p) {
+		if pos, ok := v.positions[p]; ok && pos != nil {
+			issue.Line = pos.Line
+			issue.Column = pos.Column
+			break
+		}
+	}
+	v.issues = append(v.issues, issue)
+}
+
+func parentPath(path string) string {
+	index := strings.LastIndexAny(path, ".[")
+	if index < 0 {
+		return ""
+	}
+	return path[:index]
+}
+
+func (v *fdlValidator) sorted() []ValidationIssue {
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		if v.issues[i].Line != v.issues[j].Line {
+			return v.issues[i].Line < v.issues[j].Line
+		}
+		return v.issues[i].Column < v.issues[j].Column
+	})
+	return v.issues
+}
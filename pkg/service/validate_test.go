@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeValidationFDL(t *testing.T, content string, scripts ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, script), []byte("#!/bin/bash\n"), 0o700); err != nil {
+			t.Fatalf("writing script: %v", err)
+		}
+	}
+	fdlPath := filepath.Join(dir, "fdl.yaml")
+	if err := os.WriteFile(fdlPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("writing fdl: %v", err)
+	}
+	return fdlPath
+}
+
+func findIssue(issues []ValidationIssue, fragment string) (ValidationIssue, bool) {
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, fragment) {
+			return issue, true
+		}
+	}
+	return ValidationIssue{}, false
+}
+
+func TestValidateFDL(t *testing.T) {
+	fdlPath := writeValidationFDL(t, `functions:
+  oscar:
+    - known:
+        name: demo
+        image: ghcr.io/demo/app:latest
+        script: script.sh
+        memory: 1Gi
+        cpu: "0.5"
+        input:
+          - storage_provider: minio.default
+            path: demo/in
+        output:
+          - storage_provider: s3.results
+            path: demo/out
+storage_providers:
+  s3:
+    results:
+      access_key: key
+      secret_key: secret
+      region: us-east-1
+`, "script.sh")
+
+	issues, err := ValidateFDL(fdlPath, ValidationOptions{})
+	if err != nil {
+		t.Fatalf("ValidateFDL returned error: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestValidateFDLReportsIssues(t *testing.T) {
+	fdlPath := writeValidationFDL(t, `functions:
+  oscar:
+    - unknown:
+        name: demo
+        image: ghcr.io/demo/app:latest
+        script: missing.sh
+        memmory: 1Gi
+        memory: lots
+        cpu: "-1"
+        input:
+          - storage_provider: minio.default
+            path: demo/in
+        output:
+          - storage_provider: s3.results
+            path: demo/out
+          - storage_provider: minio
+            path: /demo/in/
+`)
+
+	checkCluster := func(clusterID string) error {
+		if clusterID != "known" {
+			return errors.New("cluster not defined")
+		}
+		return nil
+	}
+	issues, err := ValidateFDL(fdlPath, ValidationOptions{CheckCluster: checkCluster})
+	if err != nil {
+		t.Fatalf("ValidateFDL returned error: %v", err)
+	}
+
+	issue, ok := findIssue(issues, `unknown field "memmory"`)
+	if !ok || issue.Line != 7 || issue.Column != 9 || issue.Path != "functions.oscar[0].unknown.memmory" {
+		t.Fatalf("unexpected unknown field issue: %+v", issue)
+	}
+	if issue, ok := findIssue(issues, `invalid memory quantity "lots"`); !ok || issue.Line != 8 {
+		t.Fatalf("expected invalid memory issue at line 8, got %+v", issues)
+	}
+	if _, ok := findIssue(issues, `cpu quantity "-1" must be greater than zero`); !ok {
+		t.Fatalf("expected invalid cpu issue, got %+v", issues)
+	}
+	if issue, ok := findIssue(issues, "missing.sh"); !ok || issue.Line != 6 {
+		t.Fatalf("expected missing script issue at line 6, got %+v", issues)
+	}
+	if issue, ok := findIssue(issues, `"s3.results" is not declared`); !ok || issue.Line != 14 {
+		t.Fatalf("expected undeclared provider issue at line 14, got %+v", issues)
+	}
+	if issue, ok := findIssue(issues, "cluster not defined"); !ok || issue.Line != 3 {
+		t.Fatalf("expected cluster issue at line 3, got %+v", issues)
+	}
+	if issue, ok := findIssue(issues, "will trigger a new invocation"); !ok || issue.Severity != SeverityWarning {
+		t.Fatalf("expected recursive output warning, got %+v", issues)
+	}
+
+	for i := 1; i < len(issues); i++ {
+		if issues[i].Line < issues[i-1].Line {
+			t.Fatalf("expected issues sorted by line, got %+v", issues)
+		}
+	}
+}
+
+func TestValidateFDLSyntaxError(t *testing.T) {
+	fdlPath := writeValidationFDL(t, "functions:\n  oscar:\n    - known:\n        name: [demo\n")
+
+	issues, err := ValidateFDL(fdlPath, ValidationOptions{})
+	if err != nil {
+		t.Fatalf("ValidateFDL returned error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Severity != SeverityError || issues[0].Line == 0 {
+		t.Fatalf("expected a positioned syntax error, got %+v", issues)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright (C) GRyCAP - I3M - UPV
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/grycap/oscar-cli/pkg/cluster"
+	"github.com/grycap/oscar/v3/pkg/types"
+)
+
+// CreateBucket creates a bucket in the cluster MinIO provider with the visibility and allowed users of the given info.
+func CreateBucket(c *cluster.Cluster, bucket *BucketInfo) error {
+	return CreateBucketWithContext(context.Background(), c, bucket)
+}
+
+// CreateBucketWithContext creates a bucket in the cluster MinIO provider with the visibility and allowed users of the given info honouring context cancellation
+func CreateBucketWithContext(ctx context.Context, c *cluster.Cluster, bucket *BucketInfo) error {
+	if c == nil {
+		return errors.New("cluster configuration not provided")
+	}
+	if bucket == nil || strings.TrimSpace(bucket.Name) == "" {
+		return errors.New("bucket name is required")
+	}
+
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return cluster.ErrParsingEndpoint
+	}
+	endpoint.Path = path.Join(endpoint.Path, "system", "buckets")
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"bucket_name":   strings.TrimSpace(bucket.Name),
+		"visibility":    bucket.Visibility,
+		"allowed_users": bucket.AllowedUsers,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return cluster.ErrMakingRequest
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client, err := c.GetClientSafe()
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return cluster.ErrSendingRequest
+	}
+	defer res.Body.Close()
+
+	return cluster.CheckStatusCode(res)
+}
+
+// DownloadBucket mirrors the objects of a bucket of the cluster MinIO provider into a local directory and returns the number of files downloaded.
+func DownloadBucket(ctx context.Context, c *cluster.Cluster, bucketName, dir string) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	result, err := ListBucketObjectsWithOptionsContext(ctx, c, bucketName, &BucketListOptions{AutoPaginate: true})
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := clusterS3Client(c)
+	if err != nil {
+		return 0, err
+	}
+	downloader := s3manager.NewDownloaderWithClient(client)
+
+	downloaded := 0
+	for _, object := range result.Objects {
+		if strings.HasSuffix(object.Name, "/") {
+			// Skip folder markers
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return downloaded, err
+		}
+
+		localPath, err := localObjectPath(dir, object.Name)
+		if err != nil {
+			return downloaded, err
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return downloaded, fmt.Errorf("unable to create the directory \"%s\"", filepath.Dir(localPath))
+		}
+
+		if err := downloadObject(ctx, downloader, bucketName, object.Name, localPath); err != nil {
+			return downloaded, fmt.Errorf("downloading \"%s\": %w", path.Join(bucketName, object.Name), err)
+		}
+		downloaded++
+	}
+
+	return downloaded, nil
+}
+
+// UploadBucket uploads the files of a local directory to a bucket of the cluster MinIO provider, keeping their relative paths, and returns the number of files uploaded.
+func UploadBucket(ctx context.Context, c *cluster.Cluster, bucketName, dir string) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	client, err := clusterS3Client(c)
+	if err != nil {
+		return 0, err
+	}
+	uploader := s3manager.NewUploaderWithClient(client)
+
+	uploaded := 0
+	err = filepath.WalkDir(dir, func(localPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, localPath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if err := uploadObject(ctx, uploader, bucketName, key, localPath); err != nil {
+			return fmt.Errorf("uploading \"%s\": %w", path.Join(bucketName, key), err)
+		}
+		uploaded++
+		return nil
+	})
+
+	return uploaded, err
+}
+
+// clusterS3Client resolves the cluster MinIO provider and builds a S3 client for it, so mirroring a bucket doesn't request the cluster config for every object
+func clusterS3Client(c *cluster.Cluster) (*s3.S3, error) {
+	prov, err := getProvider(c, DefaultStorageProvider[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	minioProvider, ok := prov.(*types.MinIOProvider)
+	if !ok {
+		return nil, errors.New("invalid provider")
+	}
+	return minioProvider.GetS3Client(), nil
+}
+
+// downloadObject downloads the object key of a bucket into localPath
+func downloadObject(ctx context.Context, downloader *s3manager.Downloader, bucketName, key, localPath string) error {
+	file, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("unable to create the file \"%s\"", localPath)
+	}
+	defer file.Close()
+
+	_, err = downloader.DownloadWithContext(ctx, file, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// uploadObject uploads the file at localPath as the object key of a bucket
+func uploadObject(ctx context.Context, uploader *s3manager.Uploader, bucketName, key, localPath string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("unable to read the file \"%s\"", localPath)
+	}
+	defer file.Close()
+
+	_, err = uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+		Body:   file,
+	})
+	return err
+}
+
+// localObjectPath returns the path of an object inside dir, rejecting object names that would escape from it
+func localObjectPath(dir, objectName string) (string, error) {
+	localPath := filepath.Join(dir, filepath.FromSlash(objectName))
+	relative, err := filepath.Rel(dir, localPath)
+	if err != nil || relative == "." || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the object name \"%s\" is not valid", objectName)
+	}
+	return localPath, nil
+}
+
+// CopyBucket copies the objects of a bucket of the src cluster MinIO provider to a bucket of the dst cluster MinIO provider and returns the number of files copied.
+func CopyBucket(ctx context.Context, src *cluster.Cluster, srcBucket string, dst *cluster.Cluster, dstBucket string) (int, error) {
+	tempDir, err := os.MkdirTemp("", "oscar-cli-copy-*")
+	if err != nil {
+		return 0, fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := DownloadBucket(ctx, src, srcBucket, tempDir); err != nil {
+		return 0, err
+	}
+
+	return UploadBucket(ctx, dst, dstBucket, tempDir)
+}
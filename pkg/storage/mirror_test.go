@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grycap/oscar-cli/pkg/cluster"
+)
+
+func TestCreateBucket(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/system/buckets" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decoding payload: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := &cluster.Cluster{Endpoint: server.URL}
+	if err := CreateBucket(c, &BucketInfo{Name: "data", Visibility: "private"}); err != nil {
+		t.Fatalf("CreateBucket returned error: %v", err)
+	}
+	if payload["bucket_name"] != "data" || payload["visibility"] != "private" {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+
+	if err := CreateBucket(c, &BucketInfo{Name: " "}); err == nil {
+		t.Fatalf("expected error for empty bucket name")
+	}
+}
+
+func TestLocalObjectPath(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := localObjectPath(dir, "folder/file.txt")
+	if err != nil {
+		t.Fatalf("localObjectPath returned error: %v", err)
+	}
+	if got != filepath.Join(dir, "folder", "file.txt") {
+		t.Fatalf("unexpected path %s", got)
+	}
+
+	for _, name := range []string{"../outside.txt", "folder/../../outside.txt", "."} {
+		if _, err := localObjectPath(dir, name); err == nil {
+			t.Fatalf("expected error for object name %q", name)
+		}
+	}
+}
+
+func TestUploadAndDownloadBucketResolveProviderOnce(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	configCalls := 0
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/system/config":
+			configCalls++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"minio_provider":{"access_key":"ak","secret_key":"sk","region":"us-east-1","endpoint":%q,"verify":false}}`, server.URL)
+		case r.URL.Path == "/system/buckets/data":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"objects":[`)
+			first := true
+			for key := range objects {
+				if !first {
+					fmt.Fprint(w, ",")
+				}
+				first = false
+				fmt.Fprintf(w, `{"object_name":%q,"size_bytes":%d}`, key, len(objects[key]))
+			}
+			fmt.Fprint(w, `],"is_truncated":false}`)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/data/"):
+			body, _ := io.ReadAll(r.Body)
+			objects[strings.TrimPrefix(r.URL.Path, "/data/")] = body
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/data/"):
+			body, ok := objects[strings.TrimPrefix(r.URL.Path, "/data/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			_, _ = w.Write(body)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := &cluster.Cluster{Endpoint: server.URL, SSLVerify: false}
+
+	src := t.TempDir()
+	files := map[string]string{"a.txt": "alpha", filepath.Join("nested", "b.txt"): "beta", "c.txt": "gamma"}
+	for name, content := range files {
+		localPath := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+		if err := os.WriteFile(localPath, []byte(content), 0o600); err != nil {
+			t.Fatalf("writing file: %v", err)
+		}
+	}
+
+	uploaded, err := UploadBucket(context.Background(), c, "data", src)
+	if err != nil {
+		t.Fatalf("UploadBucket returned error: %v", err)
+	}
+	if uploaded != len(files) {
+		t.Fatalf("expected %d uploaded files, got %d", len(files), uploaded)
+	}
+
+	dst := t.TempDir()
+	downloaded, err := DownloadBucket(context.Background(), c, "data", dst)
+	if err != nil {
+		t.Fatalf("DownloadBucket returned error: %v", err)
+	}
+	if downloaded != len(files) {
+		t.Fatalf("expected %d downloaded files, got %d", len(files), downloaded)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Fatalf("unexpected content for %s: %q", name, data)
+		}
+	}
+
+	if configCalls != 2 {
+		t.Fatalf("expected the cluster config to be requested once per bucket transfer, got %d requests", configCalls)
+	}
+}
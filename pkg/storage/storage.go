@@ -480,6 +480,11 @@ func parseBucketTimestamp(ts string) (time.Time, bool) {
 
 // DeleteBucket removes a bucket from the specified cluster.
 func DeleteBucket(c *cluster.Cluster, name string) error {
+	return DeleteBucketWithContext(context.Background(), c, name)
+}
+
+// DeleteBucketWithContext removes a bucket from the specified cluster honouring context cancellation
+func DeleteBucketWithContext(ctx context.Context, c *cluster.Cluster, name string) error {
 	if c == nil {
 		return errors.New("cluster configuration not provided")
 	}
@@ -494,7 +499,7 @@ func DeleteBucket(c *cluster.Cluster, name string) error {
 	}
 	endpoint.Path = path.Join(endpoint.Path, "system", "buckets", trimmed)
 
-	req, err := http.NewRequest(http.MethodDelete, endpoint.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint.String(), nil)
 	if err != nil {
 		return cluster.ErrMakingRequest
 	}
@@ -611,6 +616,11 @@ func GetFile(c *cluster.Cluster, svcName, providerString, remotePath, localPath
 
 // GetFileWithService downloads a file using a pre-fetched service definition.
 func GetFileWithService(c *cluster.Cluster, svc *types.Service, providerString, remotePath, localPath string, opt *TransferOption) error {
+	return GetFileWithServiceContext(context.Background(), c, svc, providerString, remotePath, localPath, opt)
+}
+
+// GetFileWithServiceContext downloads a file using a pre-fetched service definition honouring context cancellation.
+func GetFileWithServiceContext(ctx context.Context, c *cluster.Cluster, svc *types.Service, providerString, remotePath, localPath string, opt *TransferOption) error {
 	if svc == nil {
 		return errors.New("service definition not provided")
 	}
@@ -641,7 +651,7 @@ func GetFileWithService(c *cluster.Cluster, svc *types.Service, providerString,
 	case types.S3Provider:
 		var total int64
 		if showProgress {
-			head, err := v.GetS3Client().HeadObject(&s3.HeadObjectInput{
+			head, err := v.GetS3Client().HeadObjectWithContext(ctx, &s3.HeadObjectInput{
 				Bucket: aws.String(splitPath[0]),
 				Key:    aws.String(splitPath[1]),
 			})
@@ -660,7 +670,7 @@ func GetFileWithService(c *cluster.Cluster, svc *types.Service, providerString,
 		}
 
 		downloader := s3manager.NewDownloaderWithClient(v.GetS3Client())
-		_, err := downloader.Download(writer, &s3.GetObjectInput{
+		_, err := downloader.DownloadWithContext(ctx, writer, &s3.GetObjectInput{
 			Bucket: aws.String(splitPath[0]),
 			Key:    aws.String(splitPath[1]),
 		})
@@ -670,7 +680,7 @@ func GetFileWithService(c *cluster.Cluster, svc *types.Service, providerString,
 	case *types.MinIOProvider:
 		var total int64
 		if showProgress {
-			head, err := v.GetS3Client().HeadObject(&s3.HeadObjectInput{
+			head, err := v.GetS3Client().HeadObjectWithContext(ctx, &s3.HeadObjectInput{
 				Bucket: aws.String(splitPath[0]),
 				Key:    aws.String(splitPath[1]),
 			})
@@ -690,7 +700,7 @@ func GetFileWithService(c *cluster.Cluster, svc *types.Service, providerString,
 
 		// Repeat s3 code for correct type assertion
 		downloader := s3manager.NewDownloaderWithClient(v.GetS3Client())
-		_, err := downloader.Download(writer, &s3.GetObjectInput{
+		_, err := downloader.DownloadWithContext(ctx, writer, &s3.GetObjectInput{
 			Bucket: aws.String(splitPath[0]),
 			Key:    aws.String(splitPath[1]),
 		})
@@ -783,26 +793,147 @@ func ResolveLatestRemotePath(c *cluster.Cluster, svc *types.Service, providerStr
 		return "", err
 	}
 
-	splitPath := strings.SplitN(basePath, "/", 2)
-	if len(splitPath) == 1 {
-		splitPath = append(splitPath, "")
+	s3Client, err := s3ClientForProvider(prov)
+	if err != nil {
+		return "", errors.New("--download-latest-into is only supported for S3 or MinIO providers")
 	}
 
-	bucket := strings.TrimSpace(splitPath[0])
-	if bucket == "" {
-		return "", errors.New("remote path must include the bucket name")
+	bucket, objects, err := listRemoteObjects(s3Client, basePath)
+	if err != nil {
+		return "", err
 	}
-	prefix := strings.TrimLeft(splitPath[1], "/")
 
-	var s3Client *s3.S3
+	var latest *s3.Object
+	for _, obj := range objects {
+		if latest == nil || obj.LastModified.After(*latest.LastModified) {
+			latest = obj
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no files found under \"%s\"", basePath)
+	}
+
+	key := strings.TrimLeft(*latest.Key, "/")
+	return path.Join(bucket, key), nil
+}
+
+// ObjectSnapshot maps the remote paths of the files found under a path to their last modification time
+type ObjectSnapshot map[string]time.Time
+
+// SnapshotObjects returns the files found under the remote path of a S3 or MinIO provider
+func SnapshotObjects(c *cluster.Cluster, svc *types.Service, providerString, basePath string) (ObjectSnapshot, error) {
+	s3Client, err := serviceS3Client(c, svc, providerString)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, objects, err := listRemoteObjects(s3Client, basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := ObjectSnapshot{}
+	for _, obj := range objects {
+		snapshot[path.Join(bucket, strings.TrimLeft(*obj.Key, "/"))] = *obj.LastModified
+	}
+	return snapshot, nil
+}
+
+// DownloadNewObjects downloads into dir the files under the remote path of a S3 or MinIO provider that are not in the snapshot
+// or were modified after it, keeping their paths relative to the remote path, and returns the local paths of the downloaded files
+func DownloadNewObjects(ctx context.Context, c *cluster.Cluster, svc *types.Service, providerString, basePath, dir string, before ObjectSnapshot) ([]string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s3Client, err := serviceS3Client(c, svc, providerString)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, objects, err := listRemoteObjects(s3Client, basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimPrefix(strings.Trim(basePath, " /"), bucket)
+	prefix = strings.Trim(prefix, "/")
+
+	downloader := s3manager.NewDownloaderWithClient(s3Client)
+	downloaded := []string{}
+	for _, obj := range objects {
+		key := strings.TrimLeft(*obj.Key, "/")
+		if modified, ok := before[path.Join(bucket, key)]; ok && !obj.LastModified.After(modified) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return downloaded, err
+		}
+
+		relative := key
+		if prefix != "" && strings.HasPrefix(key, prefix+"/") {
+			relative = strings.TrimPrefix(key, prefix+"/")
+		}
+		localPath, err := localObjectPath(dir, relative)
+		if err != nil {
+			return downloaded, err
+		}
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return downloaded, fmt.Errorf("unable to create the directory \"%s\"", filepath.Dir(localPath))
+		}
+		if err := downloadObject(ctx, downloader, bucket, key, localPath); err != nil {
+			return downloaded, fmt.Errorf("downloading \"%s\": %w", path.Join(bucket, key), err)
+		}
+		downloaded = append(downloaded, localPath)
+	}
+
+	return downloaded, nil
+}
+
+// serviceS3Client resolves a S3 or MinIO provider of a service and builds a S3 client for it
+func serviceS3Client(c *cluster.Cluster, svc *types.Service, providerString string) (*s3.S3, error) {
+	if svc == nil {
+		return nil, errors.New("service definition not provided")
+	}
+
+	prov, err := getProvider(c, providerString, svc.StorageProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Client, err := s3ClientForProvider(prov)
+	if err != nil {
+		return nil, fmt.Errorf("the storage provider \"%s\" is not a S3 or MinIO provider", providerString)
+	}
+	return s3Client, nil
+}
+
+func s3ClientForProvider(prov interface{}) (*s3.S3, error) {
 	switch v := prov.(type) {
 	case types.S3Provider:
-		s3Client = v.GetS3Client()
+		return v.GetS3Client(), nil
+	case *types.S3Provider:
+		return v.GetS3Client(), nil
 	case *types.MinIOProvider:
-		s3Client = v.GetS3Client()
+		return v.GetS3Client(), nil
 	default:
-		return "", errors.New("--download-latest-into is only supported for S3 or MinIO providers")
+		return nil, errors.New("invalid provider")
+	}
+}
+
+// listRemoteObjects returns the bucket of the remote path and the files found under it, skipping folder markers
+func listRemoteObjects(s3Client *s3.S3, remotePath string) (string, []*s3.Object, error) {
+	remotePath = strings.Trim(remotePath, " /")
+	splitPath := strings.SplitN(remotePath, "/", 2)
+	if len(splitPath) == 1 {
+		splitPath = append(splitPath, "")
+	}
+
+	bucket := strings.TrimSpace(splitPath[0])
+	if bucket == "" {
+		return "", nil, errors.New("remote path must include the bucket name")
 	}
+	prefix := strings.TrimLeft(splitPath[1], "/")
 
 	input := &s3.ListObjectsInput{
 		Bucket: aws.String(bucket),
@@ -811,8 +942,8 @@ func ResolveLatestRemotePath(c *cluster.Cluster, svc *types.Service, providerStr
 		input.Prefix = aws.String(prefix)
 	}
 
-	var latest *s3.Object
-	err = s3Client.ListObjectsPages(input, func(page *s3.ListObjectsOutput, last bool) bool {
+	objects := []*s3.Object{}
+	err := s3Client.ListObjectsPages(input, func(page *s3.ListObjectsOutput, last bool) bool {
 		for _, obj := range page.Contents {
 			if obj == nil || obj.Key == nil || obj.LastModified == nil {
 				continue
@@ -820,22 +951,15 @@ func ResolveLatestRemotePath(c *cluster.Cluster, svc *types.Service, providerStr
 			if obj.Size != nil && *obj.Size == 0 && strings.HasSuffix(*obj.Key, "/") {
 				continue
 			}
-			if latest == nil || obj.LastModified.After(*latest.LastModified) {
-				latest = obj
-			}
+			objects = append(objects, obj)
 		}
 		return true
 	})
 	if err != nil {
-		return "", err
-	}
-
-	if latest == nil || latest.Key == nil {
-		return "", fmt.Errorf("no files found under \"%s\"", basePath)
+		return "", nil, err
 	}
 
-	key := strings.TrimLeft(*latest.Key, "/")
-	return path.Join(bucket, key), nil
+	return bucket, objects, nil
 }
 
 // PutFile uploads a file to a storage provider
@@ -844,15 +968,20 @@ func PutFile(c *cluster.Cluster, svcName, providerString, localPath, remotePath
 	if err != nil {
 		return err
 	}
-	return putFile(c, svc, providerString, localPath, remotePath, opt)
+	return putFile(context.Background(), c, svc, providerString, localPath, remotePath, opt)
 }
 
 // PutFileWithService uploads a file using a pre-fetched service definition.
 func PutFileWithService(c *cluster.Cluster, svc *types.Service, providerString, localPath, remotePath string, opt *TransferOption) error {
-	return putFile(c, svc, providerString, localPath, remotePath, opt)
+	return putFile(context.Background(), c, svc, providerString, localPath, remotePath, opt)
+}
+
+// PutFileWithServiceContext uploads a file using a pre-fetched service definition honouring context cancellation.
+func PutFileWithServiceContext(ctx context.Context, c *cluster.Cluster, svc *types.Service, providerString, localPath, remotePath string, opt *TransferOption) error {
+	return putFile(ctx, c, svc, providerString, localPath, remotePath, opt)
 }
 
-func putFile(c *cluster.Cluster, svc *types.Service, providerString, localPath, remotePath string, opt *TransferOption) error {
+func putFile(ctx context.Context, c *cluster.Cluster, svc *types.Service, providerString, localPath, remotePath string, opt *TransferOption) error {
 	if svc == nil {
 		return errors.New("service definition not provided")
 	}
@@ -894,7 +1023,7 @@ func putFile(c *cluster.Cluster, svc *types.Service, providerString, localPath,
 	switch v := prov.(type) {
 	case types.S3Provider:
 		uploader := s3manager.NewUploaderWithClient(v.GetS3Client())
-		_, err := uploader.Upload(&s3manager.UploadInput{
+		_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 			Bucket: aws.String(splitPath[0]),
 			Key:    aws.String(splitPath[1]),
 			Body:   reader,
@@ -904,7 +1033,7 @@ func putFile(c *cluster.Cluster, svc *types.Service, providerString, localPath,
 		}
 	case *types.MinIOProvider:
 		uploader := s3manager.NewUploaderWithClient(v.GetS3Client())
-		_, err := uploader.Upload(&s3manager.UploadInput{
+		_, err := uploader.UploadWithContext(ctx, &s3manager.UploadInput{
 			Bucket: aws.String(splitPath[0]),
 			Key:    aws.String(splitPath[1]),
 			Body:   reader,
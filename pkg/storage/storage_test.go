@@ -1,10 +1,13 @@
 package storage
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -274,3 +277,51 @@ func TestParseBucketTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloadNewObjects(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/system/config":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"minio_provider":{"access_key":"ak","secret_key":"sk","region":"us-east-1","endpoint":%q,"verify":false}}`, server.URL)
+		case r.Method == http.MethodGet && strings.Trim(r.URL.Path, "/") == "out":
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+<Name>out</Name><Prefix>results</Prefix><IsTruncated>false</IsTruncated>
+<Contents><Key>results/old.txt</Key><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>3</Size></Contents>
+<Contents><Key>results/</Key><LastModified>2024-01-01T00:00:00.000Z</LastModified><Size>0</Size></Contents>
+<Contents><Key>results/nested/new.txt</Key><LastModified>2024-01-02T00:00:00.000Z</LastModified><Size>3</Size></Contents>
+</ListBucketResult>`)
+		case r.Method == http.MethodGet && r.URL.Path == "/out/results/nested/new.txt":
+			w.Header().Set("Content-Length", "3")
+			fmt.Fprint(w, "new")
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.String())
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := &cluster.Cluster{Endpoint: server.URL}
+	svc := &types.Service{Name: "demo"}
+	before := ObjectSnapshot{"out/results/old.txt": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	dir := t.TempDir()
+	files, err := DownloadNewObjects(context.Background(), c, svc, "minio.default", "out/results", dir, before)
+	if err != nil {
+		t.Fatalf("DownloadNewObjects returned error: %v", err)
+	}
+	expected := filepath.Join(dir, "nested", "new.txt")
+	if len(files) != 1 || files[0] != expected {
+		t.Fatalf("expected only %s to be downloaded, got %v", expected, files)
+	}
+	content, err := os.ReadFile(expected)
+	if err != nil {
+		t.Fatalf("reading downloaded file: %v", err)
+	}
+	if string(content) != "new" {
+		t.Fatalf("unexpected content %q", content)
+	}
+}